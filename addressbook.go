@@ -0,0 +1,280 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// Alias is a named template of a Party Address, e.g., "hlr-primary".
+//
+// The address is routed on GT if GT is set, otherwise on SSN. The GT is always
+// built with GTITTNPESNAI.
+type Alias struct {
+	Name      string
+	PointCode params.PointCode // 0 to omit
+	SSN       uint8            // 0 to omit
+
+	GT                       string // digits
+	TranslationType          params.TranslationType
+	NumberingPlan            params.NumberingPlan
+	NatureOfAddressIndicator params.NatureOfAddressIndicator
+
+	// RouteOnSSN forces the address with GT to be routed on SSN.
+	RouteOnSSN bool
+}
+
+// NewAlias creates a new Alias routed on GT with the E.164 numbering plan and the
+// international number. Give empty gt to create an Alias routed on SSN.
+func NewAlias(name string, pc params.PointCode, ssn uint8, gt string) *Alias {
+	return &Alias{
+		Name:                     name,
+		PointCode:                pc,
+		SSN:                      ssn,
+		GT:                       gt,
+		NumberingPlan:            params.NPISDNTelephony,
+		NatureOfAddressIndicator: params.NAIInternationalNumber,
+	}
+}
+
+// Validate returns error if the Alias cannot make a Party Address.
+func (a *Alias) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("alias without name")
+	}
+	if strings.Trim(a.GT, "0123456789") != "" {
+		return fmt.Errorf("alias %s: invalid GT digits: %s", a.Name, a.GT)
+	}
+	if a.GT == "" && a.SSN == 0 {
+		return fmt.Errorf("alias %s: neither GT nor SSN is set", a.Name)
+	}
+	if a.PointCode > params.MaxITUPointCode {
+		return fmt.Errorf("alias %s: point code %s does not fit in 14 bits", a.Name, a.PointCode)
+	}
+	return nil
+}
+
+// PartyAddress returns a new PartyAddress built from the Alias. Give
+// params.PCodeCalledPartyAddress or params.PCodeCallingPartyAddress as code.
+func (a *Alias) PartyAddress(code params.ParameterNameCode) (*params.PartyAddress, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	hasPC, hasSSN := a.PointCode != 0, a.SSN != 0
+	if a.GT == "" {
+		ai := params.NewAddressIndicator(hasPC, hasSSN, true, params.GTINoGT)
+		return params.NewPartyAddress(code, ai, a.PointCode, a.SSN, nil), nil
+	}
+
+	gt := params.NewGlobalTitle(
+		params.GTITTNPESNAI,
+		a.TranslationType,
+		a.NumberingPlan,
+		params.ESBCDEven,
+		a.NatureOfAddressIndicator,
+		nil,
+	)
+	if err := gt.SetDigits(a.GT); err != nil {
+		return nil, fmt.Errorf("alias %s: %w", a.Name, err)
+	}
+
+	ai := params.NewAddressIndicator(hasPC, hasSSN, a.RouteOnSSN, params.GTITTNPESNAI)
+	return params.NewPartyAddress(code, ai, a.PointCode, a.SSN, gt), nil
+}
+
+// CalledPartyAddress returns a new Called Party Address built from the Alias.
+func (a *Alias) CalledPartyAddress() (*params.PartyAddress, error) {
+	return a.PartyAddress(params.PCodeCalledPartyAddress)
+}
+
+// CallingPartyAddress returns a new Calling Party Address built from the Alias.
+func (a *Alias) CallingPartyAddress() (*params.PartyAddress, error) {
+	return a.PartyAddress(params.PCodeCallingPartyAddress)
+}
+
+// String returns the Alias in human readable format.
+func (a *Alias) String() string {
+	return fmt.Sprintf("%s: {PC: %s, SSN: %d, GT: %s, TT: %d}", a.Name, a.PointCode, a.SSN, a.GT, a.TranslationType)
+}
+
+// AddressBook is a registry of Alias by name.
+//
+// AddressBook is safe for concurrent use.
+type AddressBook struct {
+	mu      sync.RWMutex
+	aliases map[string]*Alias
+}
+
+// NewAddressBook creates a new AddressBook with the given aliases.
+func NewAddressBook(aliases ...*Alias) (*AddressBook, error) {
+	b := &AddressBook{
+		aliases: make(map[string]*Alias),
+	}
+	for _, a := range aliases {
+		if err := b.Add(a); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Add registers a. It fails if a is invalid or the name is already registered.
+func (b *AddressBook) Add(a *Alias) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.aliases[a.Name]; ok {
+		return fmt.Errorf("alias %s already exists", a.Name)
+	}
+	b.aliases[a.Name] = a
+	return nil
+}
+
+// Remove removes the Alias with the given name.
+func (b *AddressBook) Remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.aliases, name)
+}
+
+// Lookup returns the Alias with the given name.
+func (b *AddressBook) Lookup(name string) (*Alias, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	a, ok := b.aliases[name]
+	return a, ok
+}
+
+// Names returns the names of the registered aliases in order.
+func (b *AddressBook) Names() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	names := make([]string, 0, len(b.aliases))
+	for name := range b.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CalledPartyAddress returns a new Called Party Address of the Alias with the given name.
+func (b *AddressBook) CalledPartyAddress(name string) (*params.PartyAddress, error) {
+	a, err := b.alias(name)
+	if err != nil {
+		return nil, err
+	}
+	return a.CalledPartyAddress()
+}
+
+// CallingPartyAddress returns a new Calling Party Address of the Alias with the given name.
+func (b *AddressBook) CallingPartyAddress(name string) (*params.PartyAddress, error) {
+	a, err := b.alias(name)
+	if err != nil {
+		return nil, err
+	}
+	return a.CallingPartyAddress()
+}
+
+// PointCode returns the point code of the Alias with the given name.
+func (b *AddressBook) PointCode(name string) (params.PointCode, error) {
+	a, err := b.alias(name)
+	if err != nil {
+		return 0, err
+	}
+	return a.PointCode, nil
+}
+
+func (b *AddressBook) alias(name string) (*Alias, error) {
+	a, ok := b.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown alias: %s", name)
+	}
+	return a, nil
+}
+
+// aliasJSON is an Alias in the JSON representation of AddressBook.
+type aliasJSON struct {
+	PointCode  string `json:"pc"`
+	SSN        uint8  `json:"ssn"`
+	GT         string `json:"gt"`
+	TT         uint8  `json:"tt"`
+	NP         *uint8 `json:"np"`
+	NAI        *uint8 `json:"nai"`
+	RouteOnSSN bool   `json:"routeOnSSN"`
+}
+
+// ReadAddressBook reads an AddressBook in JSON from r.
+//
+// The JSON is an object of the aliases keyed by name. The point code is a string
+// in ITU-T 3-8-3 format or in decimal. The numbering plan and the nature of address
+// indicator default to E.164 and international number.
+//
+//	{
+//	  "hlr-primary": {"pc": "1-2-3", "ssn": 6, "gt": "819012345678"},
+//	  "stp-a":       {"pc": "1-2-1", "ssn": 1}
+//	}
+func ReadAddressBook(r io.Reader) (*AddressBook, error) {
+	var entries map[string]aliasJSON
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode address book: %w", err)
+	}
+
+	b, _ := NewAddressBook()
+	for name, e := range entries {
+		a := NewAlias(name, 0, e.SSN, e.GT)
+		a.TranslationType = params.TranslationType(e.TT)
+		a.RouteOnSSN = e.RouteOnSSN
+		if e.NP != nil {
+			a.NumberingPlan = params.NumberingPlan(*e.NP)
+		}
+		if e.NAI != nil {
+			a.NatureOfAddressIndicator = params.NatureOfAddressIndicator(*e.NAI)
+		}
+		if e.PointCode != "" {
+			pc, err := params.ParsePointCode(e.PointCode, params.PointCodeFormatITU)
+			if err != nil {
+				return nil, fmt.Errorf("alias %s: %w", name, err)
+			}
+			a.PointCode = pc
+		}
+
+		if err := b.Add(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+// LoadAddressBook reads an AddressBook from the JSON file at path.
+// See ReadAddressBook for the format.
+func LoadAddressBook(path string) (*AddressBook, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadAddressBook(f)
+}
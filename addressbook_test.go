@@ -0,0 +1,90 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+const addressBookJSON = `{
+	"hlr-primary": {"pc": "1-2-3", "ssn": 6, "gt": "819012345678"},
+	"msc-1":       {"ssn": 8, "gt": "8190000", "tt": 1, "nai": 3},
+	"stp-a":       {"pc": "2066", "ssn": 1}
+}`
+
+func TestAddressBook(t *testing.T) {
+	book, err := sccp.ReadAddressBook(strings.NewReader(addressBookJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := book.Names(), []string{"hlr-primary", "msc-1", "stp-a"}; !verify.Values(t, "", got, want) {
+		t.Fail()
+	}
+
+	cases := []struct {
+		name       string
+		serialized []byte
+	}{
+		{"hlr-primary", []byte{0x0d, 0x13, 0x13, 0x08, 0x06, 0x00, 0x12, 0x04, 0x18, 0x09, 0x21, 0x43, 0x65, 0x87}},
+		{"msc-1", []byte{0x09, 0x12, 0x08, 0x01, 0x11, 0x03, 0x18, 0x09, 0x00, 0xf0}},
+		{"stp-a", []byte{0x04, 0x43, 0x12, 0x08, 0x01}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cdpa, err := book.CalledPartyAddress(c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := make([]byte, cdpa.MarshalLen())
+			if _, err := cdpa.Write(b); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := b, c.serialized; !verify.Values(t, "", got, want) {
+				t.Fail()
+			}
+		})
+	}
+
+	if got, err := book.PointCode("stp-a"); err != nil || got != params.NewITUPointCode(1, 2, 2) {
+		t.Errorf("got %s, %v, want 1-2-2", got, err)
+	}
+	if _, err := book.CallingPartyAddress("unknown"); err == nil {
+		t.Error("got no error for unknown alias")
+	}
+}
+
+func TestAddressBookErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		json        string
+	}{
+		{"Unknown field", `{"a": {"ssn": 6, "digits": "1234"}}`},
+		{"Invalid GT", `{"a": {"gt": "12AB"}}`},
+		{"No GT nor SSN", `{"a": {"pc": "1-2-3"}}`},
+		{"Invalid PC", `{"a": {"pc": "8-2-3", "ssn": 6}}`},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := sccp.ReadAddressBook(strings.NewReader(c.json)); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+
+	book, err := sccp.NewAddressBook(sccp.NewAlias("a", 0, 6, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Add(sccp.NewAlias("a", 0, 7, "")); err == nil {
+		t.Error("got no error for duplicated alias")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+/*
+Command sccpgen generates SCCP UDT/XUDT traffic and reports the encode throughput
+and latency.
+
+The messages are only encoded by default. Give -udp to send each message as a UDP
+datagram to the downstream gateway under test.
+
+	sccpgen -type xudt -count 1000000 -workers 4
+	sccpgen -type udt -rate 5000 -duration 1m -cdgt 8190XXXXXXXX -udp 127.0.0.1:9000
+
+The addresses can be given by the names in an address book in JSON instead; see
+sccp.ReadAddressBook for the format.
+
+	sccpgen -book addresses.json -cd hlr-primary -cg msc-1
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/loadgen"
+	"github.com/cgngc/go-sccp/params"
+)
+
+func main() {
+	def := loadgen.DefaultConfig()
+	var (
+		typ        = flag.String("type", "xudt", "Message type to generate: udt or xudt.")
+		pcls       = flag.Int("class", def.ProtocolClass, "Protocol class: 0 or 1.")
+		retOnErr   = flag.Bool("return", def.ReturnOnError, "Set return on error in the protocol class.")
+		cdGT       = flag.String("cdgt", def.CalledGT, "Called GT template. Each X is replaced with a random digit. Empty to route on SSN.")
+		cgGT       = flag.String("cggt", def.CallingGT, "Calling GT template. Each X is replaced with a random digit. Empty to route on SSN.")
+		cdPC       = flag.String("cdpc", "", "Called point code in decimal or in the -pc-format components. Empty to omit.")
+		cgPC       = flag.String("cgpc", "", "Calling point code in decimal or in the -pc-format components. Empty to omit.")
+		pcFormat   = flag.String("pc-format", "itu", "Format of -cdpc and -cgpc: itu (zone-area-sp), ansi (network-cluster-member) or raw.")
+		cdSSN      = flag.Uint("cdssn", uint(def.CalledSSN), "Called SSN.")
+		cgSSN      = flag.Uint("cgssn", uint(def.CallingSSN), "Calling SSN.")
+		minPayload = flag.Int("min-payload", def.MinPayload, "Minimum payload size in octets.")
+		maxPayload = flag.Int("max-payload", def.MaxPayload, "Maximum payload size in octets.")
+		rate       = flag.Float64("rate", 0, "Messages per second per worker. 0 is as fast as possible.")
+		count      = flag.Int("count", def.Count, "Messages per worker. 0 is unlimited.")
+		duration   = flag.Duration("duration", 0, "Time to generate messages for. 0 is unlimited.")
+		workers    = flag.Int("workers", 1, "Number of generators running in parallel.")
+		seed       = flag.Int64("seed", time.Now().UnixNano(), "Seed of the random source.")
+		udpAddr    = flag.String("udp", "", "Remote IP and Port to send the messages to over UDP. Empty to encode only.")
+		bookPath   = flag.String("book", "", "Address book file in JSON to look up -cd and -cg in.")
+		cdAlias    = flag.String("cd", "", "Name of the called address in the address book. Overrides -cdgt, -cdpc and -cdssn.")
+		cgAlias    = flag.String("cg", "", "Name of the calling address in the address book. Overrides -cggt, -cgpc and -cgssn.")
+	)
+	flag.Parse()
+
+	cfg := def
+	switch strings.ToLower(*typ) {
+	case "udt":
+		cfg.MessageType = sccp.MsgTypeUDT
+	case "xudt":
+		cfg.MessageType = sccp.MsgTypeXUDT
+	default:
+		log.Fatalf("Unsupported message type: %s", *typ)
+	}
+	cfg.ProtocolClass = *pcls
+	cfg.ReturnOnError = *retOnErr
+	cfg.CalledGT, cfg.CallingGT = *cdGT, *cgGT
+	var pcf params.PointCodeFormat
+	switch strings.ToLower(*pcFormat) {
+	case "itu":
+		pcf = params.PointCodeFormatITU
+	case "ansi":
+		pcf = params.PointCodeFormatANSI
+	case "raw":
+		pcf = params.PointCodeFormatRaw
+	default:
+		log.Fatalf("Unsupported point code format: %s", *pcFormat)
+	}
+	for _, pc := range []struct {
+		s   string
+		dst *params.PointCode
+	}{{*cdPC, &cfg.CalledPC}, {*cgPC, &cfg.CallingPC}} {
+		if pc.s == "" {
+			continue
+		}
+		v, err := params.ParsePointCode(pc.s, pcf)
+		if err != nil {
+			log.Fatalf("Invalid point code: %s", err)
+		}
+		*pc.dst = v
+	}
+	cfg.CalledSSN, cfg.CallingSSN = uint8(*cdSSN), uint8(*cgSSN)
+	if *cdAlias != "" || *cgAlias != "" {
+		if *bookPath == "" {
+			log.Fatal("-book is required to use -cd or -cg")
+		}
+		book, err := sccp.LoadAddressBook(*bookPath)
+		if err != nil {
+			log.Fatalf("Failed to load address book: %s", err)
+		}
+		for _, alias := range []struct {
+			name string
+			set  func(*sccp.Alias) error
+		}{{*cdAlias, cfg.SetCalled}, {*cgAlias, cfg.SetCalling}} {
+			if alias.name == "" {
+				continue
+			}
+			a, ok := book.Lookup(alias.name)
+			if !ok {
+				log.Fatalf("Unknown alias: %s", alias.name)
+			}
+			if err := alias.set(a); err != nil {
+				log.Fatalf("Invalid alias: %s", err)
+			}
+		}
+	}
+	cfg.MinPayload, cfg.MaxPayload = *minPayload, *maxPayload
+	cfg.Rate = *rate
+	cfg.Count = *count
+	cfg.Duration = *duration
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total []*loadgen.Stats
+	)
+	for i := 0; i < *workers; i++ {
+		c := cfg
+		c.Seed = *seed + int64(i)
+		g, err := loadgen.NewGenerator(c)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %s", err)
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var t sccp.Transport
+			if *udpAddr != "" {
+				conn, err := net.Dial("udp", *udpAddr)
+				if err != nil {
+					log.Printf("Worker %d failed to dial %s: %s", i, *udpAddr, err)
+					return
+				}
+				defer conn.Close()
+				t = conn
+			}
+
+			stats, err := g.Run(ctx, t)
+			if err != nil {
+				log.Printf("Worker %d stopped: %s", i, err)
+			}
+			log.Printf("Worker %d: %s", i, stats)
+
+			mu.Lock()
+			total = append(total, stats)
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	var msgs, bytes, errs uint64
+	var elapsed time.Duration
+	for _, s := range total {
+		msgs += s.Messages
+		bytes += s.Bytes
+		errs += s.Errors
+		elapsed = max(elapsed, s.Elapsed)
+	}
+	sum := &loadgen.Stats{Messages: msgs, Bytes: bytes, Errors: errs, Elapsed: elapsed}
+	log.Printf("Total: %d messages (%d bytes, %d errors) in %s (%.0f msg/s)",
+		msgs, bytes, errs, elapsed, sum.Rate(),
+	)
+}
@@ -0,0 +1,93 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"net"
+	"slices"
+)
+
+// AppendMessage appends the byte sequence generated from m to buf and returns the
+// extended buffer. It allocates only when buf does not have enough capacity.
+//
+// On error, buf is returned with its original length.
+func AppendMessage(buf []byte, m Message) ([]byte, error) {
+	l := m.MarshalLen()
+	start := len(buf)
+
+	buf = slices.Grow(buf, l)[:start+l]
+	if err := m.MarshalTo(buf[start:]); err != nil {
+		return buf[:start], fmt.Errorf("failed to marshal %s: %w", m.MessageTypeName(), err)
+	}
+
+	return buf, nil
+}
+
+// Encoder serializes many Messages into one contiguous buffer, keeping track of
+// where each of them starts.
+//
+// It is meant for bulk transfer and replay, where the buffer is sent at once or
+// split into the individual messages with Buffers for writev-style sends.
+// Encoder is not safe for concurrent use.
+type Encoder struct {
+	offsets []int
+}
+
+// NewEncoder creates a new Encoder. n is the expected number of messages, used to
+// preallocate the offsets.
+func NewEncoder(n int) *Encoder {
+	return &Encoder{offsets: make([]int, 0, n)}
+}
+
+// AppendMessage appends the byte sequence generated from m to buf and returns the
+// extended buffer, recording the offset where m starts.
+//
+// The same buf (or the one returned from the previous call) should be given until
+// Reset is called, as the offsets are relative to the beginning of it.
+func (e *Encoder) AppendMessage(buf []byte, m Message) ([]byte, error) {
+	offset := len(buf)
+	buf, err := AppendMessage(buf, m)
+	if err != nil {
+		return buf, err
+	}
+
+	e.offsets = append(e.offsets, offset)
+	return buf, nil
+}
+
+// Offsets returns the offsets of the messages appended since the last Reset.
+func (e *Encoder) Offsets() []int {
+	return e.offsets
+}
+
+// Len returns the number of messages appended since the last Reset.
+func (e *Encoder) Len() int {
+	return len(e.offsets)
+}
+
+// Message returns the i-th message in buf, which is the buffer built by AppendMessage.
+func (e *Encoder) Message(buf []byte, i int) []byte {
+	end := len(buf)
+	if i+1 < len(e.offsets) {
+		end = e.offsets[i+1]
+	}
+	return buf[e.offsets[i]:end:end]
+}
+
+// Buffers splits buf, which is the buffer built by AppendMessage, into the individual
+// messages without copying.
+func (e *Encoder) Buffers(buf []byte) net.Buffers {
+	bufs := make(net.Buffers, len(e.offsets))
+	for i := range e.offsets {
+		bufs[i] = e.Message(buf, i)
+	}
+	return bufs
+}
+
+// Reset clears the offsets so that the Encoder can be reused for a new buffer.
+func (e *Encoder) Reset() {
+	e.offsets = e.offsets[:0]
+}
@@ -0,0 +1,122 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func newTestXUDT(data []byte) *sccp.XUDT {
+	return sccp.NewXUDT(
+		1,    // Protocol Class
+		true, // Message handling
+		2,    // Hop Counter
+		params.NewCalledPartyAddress(
+			params.NewAddressIndicator(false, true, false, params.GTITTNPESNAI),
+			0, 6, // SPC, SSN
+			params.NewGlobalTitle(
+				params.GTITTNPESNAI,
+				params.TranslationType(0),
+				params.NPISDNTelephony,
+				params.ESBCDOdd,
+				params.NAIInternationalNumber,
+				[]byte{0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0x65},
+			),
+		),
+		params.NewCallingPartyAddress(
+			params.NewAddressIndicator(false, true, false, params.GTITTNPESNAI),
+			0, 7, // SPC, SSN
+			params.NewGlobalTitle(
+				params.GTITTNPESNAI,
+				params.TranslationType(0),
+				params.NPISDNTelephony,
+				params.ESBCDEven,
+				params.NAIInternationalNumber,
+				[]byte{0x89, 0x67, 0x45, 0x23, 0x01},
+			),
+		),
+		data,
+	)
+}
+
+func TestEncoder(t *testing.T) {
+	msgs := []sccp.Message{
+		newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef}),
+		newTestXUDT([]byte{0x01}),
+		newTestXUDT([]byte{0x02, 0x03}),
+	}
+
+	enc := sccp.NewEncoder(len(msgs))
+	buf := []byte{0xff} // something already in the buffer
+	for _, m := range msgs {
+		var err error
+		buf, err = enc.AppendMessage(buf, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := enc.Len(), len(msgs); got != want {
+		t.Fatalf("got %d messages, want %d", got, want)
+	}
+
+	bufs := enc.Buffers(buf)
+	for i, m := range msgs {
+		want, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := bufs[i]; !verify.Values(t, "", got, want) {
+			t.Errorf("message %d: got %x, want %x", i, got, want)
+		}
+	}
+
+	if got, want := enc.Offsets()[0], 1; got != want {
+		t.Errorf("got offset %d, want %d", got, want)
+	}
+
+	enc.Reset()
+	if got, want := enc.Len(), 0; got != want {
+		t.Errorf("got %d messages after Reset, want %d", got, want)
+	}
+}
+
+func BenchmarkEncoderAppendMessage(b *testing.B) {
+	m := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})
+	enc := sccp.NewEncoder(1000)
+	buf := make([]byte, 0, 1000*m.MarshalLen())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%1000 == 0 {
+			enc.Reset()
+			buf = buf[:0]
+		}
+
+		var err error
+		buf, err = enc.AppendMessage(buf, m)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalBinary(b *testing.B) {
+	m := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.MarshalBinary(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
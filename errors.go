@@ -8,6 +8,23 @@ import (
 	"fmt"
 )
 
+// LengthError indicates a message or a part of it exceeds the size limit.
+//
+// Length is the number of octets in Part, including the length octets of the
+// parameters in it, and Max is the maximum number of octets allowed for Part.
+// For the parts addressed by the Pointers, Max is what the Pointer can reach.
+type LengthError struct {
+	MessageType MsgType
+	Part        string
+	Length      int
+	Max         int
+}
+
+// Error returns the part of the message exceeding the limit and its length.
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("sccp: %s %s too long: %d octets, max %d", e.MessageType, e.Part, e.Length, e.Max)
+}
+
 // UnsupportedTypeError indicates the value in Version field is invalid.
 type UnsupportedTypeError uint8
 
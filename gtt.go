@@ -0,0 +1,130 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// Destination is a destination of the message resolved by the global title translation.
+type Destination struct {
+	PointCode params.PointCode
+	SSN       uint8
+}
+
+// String returns the Destination in human readable format.
+func (d Destination) String() string {
+	return fmt.Sprintf("PC=%s, SSN=%d", d.PointCode, d.SSN)
+}
+
+// GTTResult is the result of the global title translation.
+//
+// Destinations are in the order of priority; the first one is the primary and the
+// rest are the backups used when the preceding ones are unavailable.
+type GTTResult struct {
+	Prefix       string
+	Destinations []Destination
+
+	// index of the destination currently in use.
+	active atomic.Int32
+}
+
+// NewGTTResult creates a new GTTResult.
+func NewGTTResult(prefix string, dests ...Destination) *GTTResult {
+	return &GTTResult{
+		Prefix:       prefix,
+		Destinations: dests,
+	}
+}
+
+// Primary returns the primary Destination.
+func (r *GTTResult) Primary() Destination {
+	if len(r.Destinations) == 0 {
+		return Destination{}
+	}
+	return r.Destinations[0]
+}
+
+// Active returns the Destination selected last time by Router.
+func (r *GTTResult) Active() Destination {
+	i := int(r.active.Load())
+	if i >= len(r.Destinations) {
+		return Destination{}
+	}
+	return r.Destinations[i]
+}
+
+// String returns the GTTResult in human readable format.
+func (r *GTTResult) String() string {
+	return fmt.Sprintf("{Prefix: %s, Destinations: %v, Active: %s}", r.Prefix, r.Destinations, r.Active())
+}
+
+// GTT is a global title translation table that maps the GT digits to GTTResult by
+// the longest prefix match.
+//
+// GTT is safe for concurrent use.
+type GTT struct {
+	mu      sync.RWMutex
+	entries map[string]*GTTResult
+}
+
+// NewGTT creates a new GTT.
+func NewGTT() *GTT {
+	return &GTT{
+		entries: make(map[string]*GTTResult),
+	}
+}
+
+// Add registers the destinations for the GT digits starting with prefix, in the
+// order of priority. An empty prefix matches any digits.
+func (g *GTT) Add(prefix string, dests ...Destination) *GTTResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r := NewGTTResult(prefix, dests...)
+	g.entries[prefix] = r
+	return r
+}
+
+// Remove removes the entry for prefix.
+func (g *GTT) Remove(prefix string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.entries, prefix)
+}
+
+// Len returns the number of the entries.
+func (g *GTT) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return len(g.entries)
+}
+
+// Translate returns the GTTResult for the longest prefix matching the GT digits.
+func (g *GTT) Translate(digits string) (*GTTResult, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for n := len(digits); n >= 0; n-- {
+		if r, ok := g.entries[digits[:n]]; ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// TranslateAddress returns the GTTResult for the GT in the given PartyAddress.
+func (g *GTT) TranslateAddress(p *params.PartyAddress) (*GTTResult, bool) {
+	if p == nil || p.GlobalTitle == nil {
+		return nil, false
+	}
+	return g.Translate(p.Digits())
+}
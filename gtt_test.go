@@ -0,0 +1,48 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+)
+
+func TestGTTTranslate(t *testing.T) {
+	gtt := sccp.NewGTT()
+	gtt.Add("81", sccp.Destination{PointCode: 1, SSN: 6})
+	gtt.Add("8190", sccp.Destination{PointCode: 2, SSN: 6}, sccp.Destination{PointCode: 3, SSN: 6})
+	gtt.Add("819012", sccp.Destination{PointCode: 4, SSN: 7})
+
+	cases := []struct {
+		digits     string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{"8190123456", "819012", true},
+		{"8190999999", "8190", true},
+		{"8180000000", "81", true},
+		{"8", "", false},
+		{"4412345678", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.digits, func(t *testing.T) {
+			res, ok := gtt.Translate(c.digits)
+			if ok != c.wantOK {
+				t.Fatalf("got %v, want %v", ok, c.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if res.Prefix != c.wantPrefix {
+				t.Errorf("got prefix %s, want %s", res.Prefix, c.wantPrefix)
+			}
+			if got, want := res.Active(), res.Primary(); got != want {
+				t.Errorf("got active %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+// MaxMTP3MessageLength is the maximum length of a SCCP message carried over MTP3:
+// 272 octets of SIF minus 4 octets of the routing label.
+const MaxMTP3MessageLength = 268
+
+// Limits is the size limits of the messages sent by a Stack.
+//
+// The limits within a message defined in Q.713, e.g., the Pointers and the length
+// of Data to fit in one octet, are always enforced on marshaling regardless of Limits.
+type Limits struct {
+	// MaxMessageLength is the maximum length of a serialized message, which
+	// depends on the MTP payload size available. 0 disables the check.
+	MaxMessageLength int
+}
+
+// DefaultLimits returns the Limits for MTP3. M3UA allows larger messages.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxMessageLength: MaxMTP3MessageLength,
+	}
+}
+
+// Check returns *LengthError if m exceeds the Limits.
+func (l Limits) Check(m Message) error {
+	if l.MaxMessageLength <= 0 {
+		return nil
+	}
+
+	if n := m.MarshalLen(); n > l.MaxMessageLength {
+		return &LengthError{m.MessageType(), "message", n, l.MaxMessageLength}
+	}
+	return nil
+}
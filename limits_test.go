@@ -0,0 +1,231 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+)
+
+func newLongGTAddress(ssn uint8, n int) *params.PartyAddress {
+	return params.NewCalledPartyAddress(
+		params.NewAddressIndicator(false, true, false, params.GTITTNPESNAI),
+		0, ssn,
+		params.NewGlobalTitle(
+			params.GTITTNPESNAI,
+			params.TranslationType(0),
+			params.NPISDNTelephony,
+			params.ESBCDEven,
+			params.NAIInternationalNumber,
+			bytes.Repeat([]byte{0x21}, n),
+		),
+	)
+}
+
+func TestMarshalLengthLimits(t *testing.T) {
+	cdpa := params.NewCalledPartyAddress(0x42, 0, 6, nil)
+	cgpa := params.NewCallingPartyAddress(0x42, 0, 7, nil)
+	longCdPA, longCgPA := newLongGTAddress(6, 120), newLongGTAddress(7, 130)
+	addrLen := longCdPA.MarshalLen() + longCgPA.MarshalLen()
+
+	// Length is the octets of the part, and Max is the octets allowed for it.
+	cases := []struct {
+		description string
+		msg         sccp.Message
+		wantPart    string
+		wantLength  int
+		wantMax     int
+	}{
+		{
+			"UDT/Data",
+			sccp.NewUDT(1, true, cdpa, cgpa, make([]byte, 256)),
+			"Data", 256, 255,
+		},
+		{
+			"UDT/Addresses",
+			sccp.NewUDT(1, true, longCdPA, longCgPA, nil),
+			"Called/Calling Party Address", addrLen, 254,
+		},
+		{
+			"XUDT/Data",
+			sccp.NewXUDT(1, true, 15, cdpa, cgpa, make([]byte, 300)),
+			"Data", 300, 255,
+		},
+		{
+			"XUDT/Addresses",
+			sccp.NewXUDT(1, true, 15, longCdPA, longCgPA, nil),
+			"Called/Calling Party Address", addrLen, 253,
+		},
+		{
+			"XUDT/Optional parameters",
+			sccp.NewXUDT(1, true, 15, cdpa, cgpa, make([]byte, 250), params.NewImportance(1)),
+			"mandatory variable part", 3 + 3 + 251, 254,
+		},
+		{
+			"UDTS/Data",
+			sccp.NewUDTS(params.ReturnCauseSubsystemFailure, cdpa, cgpa, make([]byte, 256)),
+			"Data", 256, 255,
+		},
+		{
+			"UDTS/Addresses",
+			sccp.NewUDTS(params.ReturnCauseSubsystemFailure, longCdPA, longCgPA, nil),
+			"Called/Calling Party Address", addrLen, 254,
+		},
+		{
+			"XUDTS/Data",
+			sccp.NewXUDTS(params.ReturnCauseSubsystemFailure, 15, cdpa, cgpa, make([]byte, 256)),
+			"Data", 256, 255,
+		},
+		{
+			"XUDTS/Addresses",
+			sccp.NewXUDTS(params.ReturnCauseSubsystemFailure, 15, longCdPA, longCgPA, nil),
+			"Called/Calling Party Address", addrLen, 253,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := c.msg.MarshalBinary()
+
+			var lerr *sccp.LengthError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("got %v, want *sccp.LengthError", err)
+			}
+			if lerr.Part != c.wantPart {
+				t.Errorf("got %s, want %s", lerr.Part, c.wantPart)
+			}
+			if lerr.Length != c.wantLength || lerr.Max != c.wantMax {
+				t.Errorf("got length %d, max %d, want length %d, max %d", lerr.Length, lerr.Max, c.wantLength, c.wantMax)
+			}
+		})
+	}
+}
+
+// roundTrip serializes m, parses it back, and serializes it again. It fails if the
+// two byte sequences differ.
+func roundTrip(t *testing.T, m sccp.Message) (sccp.Message, error) {
+	t.Helper()
+
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if got, want := len(b), m.MarshalLen(); got != want {
+		t.Errorf("got %d octets, want %d", got, want)
+	}
+
+	parsed, err := sccp.ParseMessage(b)
+	if err != nil {
+		t.Fatalf("failed to parse %x: %s", b, err)
+	}
+	again, err := parsed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, b) {
+		t.Errorf("got %x, want %x", again, b)
+	}
+	return parsed, nil
+}
+
+func TestMarshalPointerBoundaries(t *testing.T) {
+	t.Run("XUDT/Data", func(t *testing.T) {
+		cdpa, cgpa := newLongGTAddress(6, 5), newLongGTAddress(7, 5)
+		// the Pointer to the optional part: 1 + CdPA + CgPA + 1 + Data <= 255
+		limit := 253 - cdpa.MarshalLen() - cgpa.MarshalLen()
+
+		for n := limit - 8; n <= limit+1; n++ {
+			data := bytes.Repeat([]byte{0xda}, n)
+			x := sccp.NewXUDT(1, true, 15, cdpa, cgpa, data, params.NewSegmentation(true, 1, 1, 0x123456))
+
+			m, err := roundTrip(t, x)
+			if n > limit {
+				var lerr *sccp.LengthError
+				if !errors.As(err, &lerr) || lerr.Part != "mandatory variable part" {
+					t.Errorf("got %v with %d octets of Data, want *sccp.LengthError", err, n)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("failed with %d octets of Data: %s", n, err)
+			}
+
+			got := m.(*sccp.XUDT)
+			if !bytes.Equal(got.Data.Value(), data) || got.Segmentation == nil {
+				t.Errorf("got %v with %d octets of Data", got, n)
+			}
+		}
+	})
+
+	for _, c := range []struct {
+		description string
+		newMsg      func(cdpa, cgpa *params.PartyAddress) sccp.Message
+		limit       int
+	}{
+		{
+			"UDT/Addresses",
+			func(cdpa, cgpa *params.PartyAddress) sccp.Message {
+				return sccp.NewUDT(1, true, cdpa, cgpa, []byte{0xda})
+			},
+			254,
+		},
+		{
+			"XUDT/Addresses",
+			func(cdpa, cgpa *params.PartyAddress) sccp.Message {
+				return sccp.NewXUDT(1, true, 15, cdpa, cgpa, []byte{0xda})
+			},
+			253,
+		},
+	} {
+		t.Run(c.description, func(t *testing.T) {
+			cgpa := params.NewCallingPartyAddress(0x42, 0, 7, nil)
+			for l := 250; l <= 255; l++ {
+				// the CdPA ends close to the end of the range of the Pointers.
+				cdpa := newLongGTAddress(6, l-cgpa.MarshalLen()-newLongGTAddress(6, 0).MarshalLen())
+
+				_, err := roundTrip(t, c.newMsg(cdpa, cgpa))
+				if l > c.limit {
+					var lerr *sccp.LengthError
+					if !errors.As(err, &lerr) || lerr.Length != l {
+						t.Errorf("got %v with %d octets of addresses, want *sccp.LengthError", err, l)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("failed with %d octets of addresses: %s", l, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLimits(t *testing.T) {
+	x := sccp.NewXUDT(
+		1, true, 15,
+		params.NewCalledPartyAddress(0x42, 0, 6, nil),
+		params.NewCallingPartyAddress(0x42, 0, 7, nil),
+		make([]byte, 255),
+	)
+	if _, err := x.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+
+	var lerr *sccp.LengthError
+	if err := sccp.DefaultLimits().Check(x); !errors.As(err, &lerr) {
+		t.Fatalf("got %v, want *sccp.LengthError", err)
+	}
+	if got, want := lerr.Max, sccp.MaxMTP3MessageLength; got != want {
+		t.Errorf("got max %d, want %d", got, want)
+	}
+
+	// M3UA allows larger messages.
+	if err := (sccp.Limits{MaxMessageLength: 4096}).Check(x); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package loadgen generates streams of SCCP unitdata messages for load-testing the
+// encoder and the downstream gateways.
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/cgngc/go-sccp/utils"
+)
+
+// Wildcard is the character in the GT templates to be replaced with a random digit.
+const Wildcard = 'X'
+
+// Config is a configuration of the traffic generated by Generator.
+type Config struct {
+	// MessageType is the type of the messages to generate: MsgTypeUDT or MsgTypeXUDT.
+	MessageType   sccp.MsgType
+	ProtocolClass int
+	ReturnOnError bool
+	HopCounter    uint8 // XUDT only
+
+	// CalledGT and CallingGT are the templates of the GT digits, in which every
+	// Wildcard is replaced with a random digit per message. The address is routed
+	// on SSN without GT if the template is empty.
+	CalledGT        string
+	CallingGT       string
+	TranslationType params.TranslationType
+	CalledPC        params.PointCode
+	CallingPC       params.PointCode
+	CalledSSN       uint8
+	CallingSSN      uint8
+
+	// CalledPartyAddress and CallingPartyAddress are used as is for every message
+	// if set, instead of the addresses built from the fields above.
+	CalledPartyAddress  *params.PartyAddress
+	CallingPartyAddress *params.PartyAddress
+
+	// MinPayload and MaxPayload are the range of the payload size in octets.
+	MinPayload int
+	MaxPayload int
+
+	// Rate is the number of messages generated per second. 0 means as fast as possible.
+	Rate float64
+
+	// Count and Duration limit the number of messages and the time Run generates
+	// messages for. 0 means unlimited.
+	Count    int
+	Duration time.Duration
+
+	// Seed is the seed of the random source. The same Seed generates the same traffic.
+	Seed int64
+}
+
+// DefaultConfig returns a Config that generates XUDT with random GTs and 16-octet payload
+// as fast as possible.
+func DefaultConfig() Config {
+	return Config{
+		MessageType:   sccp.MsgTypeXUDT,
+		ProtocolClass: 0,
+		HopCounter:    sccp.DefaultHopCounter,
+		CalledGT:      "8190XXXXXXXX",
+		CallingGT:     "8180XXXXXXXX",
+		CalledSSN:     6,
+		CallingSSN:    7,
+		MinPayload:    16,
+		MaxPayload:    16,
+		Count:         1000,
+	}
+}
+
+// SetCalled sets the Called Party Address of the Config to the one built from a.
+func (c *Config) SetCalled(a *sccp.Alias) error {
+	cdpa, err := a.CalledPartyAddress()
+	if err != nil {
+		return err
+	}
+	c.CalledPartyAddress = cdpa
+	return nil
+}
+
+// SetCalling sets the Calling Party Address of the Config to the one built from a.
+func (c *Config) SetCalling(a *sccp.Alias) error {
+	cgpa, err := a.CallingPartyAddress()
+	if err != nil {
+		return err
+	}
+	c.CallingPartyAddress = cgpa
+	return nil
+}
+
+// Validate returns error if the Config has invalid values.
+func (c Config) Validate() error {
+	switch c.MessageType {
+	case sccp.MsgTypeUDT, sccp.MsgTypeXUDT:
+	default:
+		return fmt.Errorf("unsupported message type: %s", c.MessageType)
+	}
+
+	if c.ProtocolClass != 0 && c.ProtocolClass != 1 {
+		return fmt.Errorf("invalid protocol class: %d", c.ProtocolClass)
+	}
+	if c.MinPayload < 0 || c.MaxPayload < c.MinPayload {
+		return fmt.Errorf("invalid payload size range: %d-%d", c.MinPayload, c.MaxPayload)
+	}
+	if c.MaxPayload > 0xff {
+		return fmt.Errorf("payload size %d exceeds %d", c.MaxPayload, 0xff)
+	}
+	if c.Rate < 0 {
+		return fmt.Errorf("invalid rate: %f", c.Rate)
+	}
+
+	for _, gt := range []string{c.CalledGT, c.CallingGT} {
+		if strings.Trim(gt, "0123456789"+string(Wildcard)) != "" {
+			return fmt.Errorf("invalid GT template: %s", gt)
+		}
+	}
+
+	return nil
+}
+
+// Generator generates the SCCP messages as configured in Config.
+//
+// Generator is not safe for concurrent use; create one for each goroutine.
+type Generator struct {
+	cfg Config
+	rnd *rand.Rand
+	buf []byte
+}
+
+// NewGenerator creates a new Generator.
+func NewGenerator(cfg Config) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		cfg: cfg,
+		rnd: rand.New(rand.NewSource(cfg.Seed)),
+	}, nil
+}
+
+// Config returns the Config of the Generator.
+func (g *Generator) Config() Config {
+	return g.cfg
+}
+
+// Next generates a new message.
+func (g *Generator) Next() (sccp.Message, error) {
+	cdpa := g.cfg.CalledPartyAddress
+	if cdpa == nil {
+		var err error
+		cdpa, err = g.partyAddress(params.PCodeCalledPartyAddress, g.cfg.CalledGT, g.cfg.CalledPC, g.cfg.CalledSSN)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cgpa := g.cfg.CallingPartyAddress
+	if cgpa == nil {
+		var err error
+		cgpa, err = g.partyAddress(params.PCodeCallingPartyAddress, g.cfg.CallingGT, g.cfg.CallingPC, g.cfg.CallingSSN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payload := g.payload()
+	switch g.cfg.MessageType {
+	case sccp.MsgTypeUDT:
+		return sccp.NewUDT(g.cfg.ProtocolClass, g.cfg.ReturnOnError, cdpa, cgpa, payload), nil
+	case sccp.MsgTypeXUDT:
+		return sccp.NewXUDT(g.cfg.ProtocolClass, g.cfg.ReturnOnError, g.cfg.HopCounter, cdpa, cgpa, payload), nil
+	default:
+		return nil, fmt.Errorf("unsupported message type: %s", g.cfg.MessageType)
+	}
+}
+
+// Run generates the messages until Count or Duration is reached or ctx is done,
+// and writes each of them to t if t is not nil.
+//
+// The time taken to encode each message is recorded in the returned Stats. The
+// write errors are counted and do not stop Run.
+func (g *Generator) Run(ctx context.Context, t sccp.Transport) (*Stats, error) {
+	if g.cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.cfg.Duration)
+		defer cancel()
+	}
+
+	var interval time.Duration
+	if g.cfg.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / g.cfg.Rate)
+	}
+
+	stats := &Stats{}
+	start := time.Now()
+	next := start
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for i := 0; g.cfg.Count <= 0 || i < g.cfg.Count; i++ {
+		if interval > 0 {
+			if d := time.Until(next); d > 0 {
+				timer.Reset(d)
+				select {
+				case <-ctx.Done():
+					stats.Elapsed = time.Since(start)
+					return stats, nil
+				case <-timer.C:
+				}
+			}
+			next = next.Add(interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			stats.Elapsed = time.Since(start)
+			return stats, nil
+		default:
+		}
+
+		m, err := g.Next()
+		if err != nil {
+			return stats, err
+		}
+
+		encStart := time.Now()
+		g.buf, err = sccp.AppendMessage(g.buf[:0], m)
+		if err != nil {
+			return stats, fmt.Errorf("failed to encode %s: %w", m.MessageTypeName(), err)
+		}
+		stats.record(len(g.buf), time.Since(encStart))
+
+		if t == nil {
+			continue
+		}
+		if _, err := t.Write(g.buf); err != nil {
+			stats.Errors++
+		}
+	}
+
+	stats.Elapsed = time.Since(start)
+	return stats, nil
+}
+
+func (g *Generator) partyAddress(code params.ParameterNameCode, tmpl string, pc params.PointCode, ssn uint8) (*params.PartyAddress, error) {
+	hasPC := pc != 0
+	if tmpl == "" {
+		ai := params.NewAddressIndicator(hasPC, true, true, params.GTINoGT)
+		return params.NewPartyAddress(code, ai, pc, ssn, nil), nil
+	}
+
+	digits := g.digits(tmpl)
+	bcd, err := utils.BCDEncode(digits)
+	if err != nil {
+		return nil, err
+	}
+
+	es := params.ESBCDEven
+	if len(digits)%2 != 0 {
+		es = params.ESBCDOdd
+	}
+
+	gt := params.NewGlobalTitle(
+		params.GTITTNPESNAI,
+		g.cfg.TranslationType,
+		params.NPISDNTelephony,
+		es,
+		params.NAIInternationalNumber,
+		bcd,
+	)
+	ai := params.NewAddressIndicator(hasPC, true, false, params.GTITTNPESNAI)
+	return params.NewPartyAddress(code, ai, pc, ssn, gt), nil
+}
+
+func (g *Generator) digits(tmpl string) string {
+	if strings.IndexByte(tmpl, Wildcard) < 0 {
+		return tmpl
+	}
+
+	b := []byte(tmpl)
+	for i, c := range b {
+		if c == Wildcard {
+			b[i] = '0' + byte(g.rnd.Intn(10))
+		}
+	}
+	return string(b)
+}
+
+func (g *Generator) payload() []byte {
+	n := g.cfg.MinPayload
+	if d := g.cfg.MaxPayload - g.cfg.MinPayload; d > 0 {
+		n += g.rnd.Intn(d + 1)
+	}
+
+	b := make([]byte, n)
+	g.rnd.Read(b)
+	return b
+}
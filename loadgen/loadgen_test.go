@@ -0,0 +1,154 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package loadgen_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/loadgen"
+	"github.com/cgngc/go-sccp/params"
+)
+
+type countingTransport struct {
+	n int
+}
+
+func (t *countingTransport) Read(b []byte) (int, error) { return 0, nil }
+func (t *countingTransport) Close() error               { return nil }
+
+func (t *countingTransport) Write(b []byte) (int, error) {
+	if _, err := sccp.ParseMessage(b); err != nil {
+		return 0, err
+	}
+	t.n++
+	return len(b), nil
+}
+
+func TestGenerator(t *testing.T) {
+	cfg := loadgen.DefaultConfig()
+	cfg.CalledGT = "8190XXX"
+	cfg.MinPayload, cfg.MaxPayload = 1, 32
+
+	g, err := loadgen.NewGenerator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		m, err := g.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		x, ok := m.(*sccp.XUDT)
+		if !ok {
+			t.Fatalf("got %T, want *sccp.XUDT", m)
+		}
+
+		digits := x.CalledPartyAddress.Digits()
+		if len(digits) != len(cfg.CalledGT) || !strings.HasPrefix(digits, "8190") {
+			t.Errorf("got CdGT %s, want %s", digits, cfg.CalledGT)
+		}
+		if n := x.Data.Len(); n < cfg.MinPayload || n > cfg.MaxPayload {
+			t.Errorf("got payload size %d, want %d-%d", n, cfg.MinPayload, cfg.MaxPayload)
+		}
+	}
+}
+
+func TestGeneratorRun(t *testing.T) {
+	cfg := loadgen.DefaultConfig()
+	cfg.Count = 50
+
+	g, err := loadgen.NewGenerator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &countingTransport{}
+	stats, err := g.Run(context.Background(), tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := stats.Messages, uint64(cfg.Count); got != want {
+		t.Errorf("got %d messages, want %d", got, want)
+	}
+	if got, want := tr.n, cfg.Count; got != want {
+		t.Errorf("got %d messages written, want %d", got, want)
+	}
+	if stats.Errors != 0 {
+		t.Errorf("got %d errors", stats.Errors)
+	}
+	if p := stats.Percentile(99); p < stats.MinLatency || p > stats.MaxLatency {
+		t.Errorf("got p99 %s, want within %s-%s", p, stats.MinLatency, stats.MaxLatency)
+	}
+}
+
+func TestConfigAlias(t *testing.T) {
+	cfg := loadgen.DefaultConfig()
+	cfg.SetCalled(sccp.NewAlias("hlr-primary", 0x0813, 6, "819012345678"))
+	cfg.SetCalling(sccp.NewAlias("stp-a", 0x0812, 1, ""))
+
+	g, err := loadgen.NewGenerator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := g.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := m.(*sccp.XUDT)
+	if got, want := x.CalledPartyAddress.Digits(), "819012345678"; got != want {
+		t.Errorf("got CdGT %s, want %s", got, want)
+	}
+	if got, want := x.CalledPartyAddress.SignalingPointCode, params.PointCode(0x0813); got != want {
+		t.Errorf("got CdPA PC %s, want %s", got, want)
+	}
+	if cgpa := x.CallingPartyAddress; !cgpa.RouteOnSSN() || cgpa.SubsystemNumber != 1 {
+		t.Errorf("got CgPA %s, want routed on SSN 1", cgpa.Address())
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cases := []struct {
+		description string
+		modify      func(c *loadgen.Config)
+	}{
+		{"Message type", func(c *loadgen.Config) { c.MessageType = sccp.MsgTypeCR }},
+		{"Protocol class", func(c *loadgen.Config) { c.ProtocolClass = 2 }},
+		{"Payload range", func(c *loadgen.Config) { c.MinPayload, c.MaxPayload = 10, 5 }},
+		{"GT template", func(c *loadgen.Config) { c.CalledGT = "81-90" }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			cfg := loadgen.DefaultConfig()
+			c.modify(&cfg)
+			if _, err := loadgen.NewGenerator(cfg); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
+
+func BenchmarkGeneratorRun(b *testing.B) {
+	cfg := loadgen.DefaultConfig()
+	cfg.Count = b.N
+
+	g, err := loadgen.NewGenerator(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	if _, err := g.Run(context.Background(), nil); err != nil {
+		b.Fatal(err)
+	}
+}
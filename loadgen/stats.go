@@ -0,0 +1,108 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package loadgen
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// Stats is the statistics of the messages generated by Generator.Run.
+type Stats struct {
+	Messages uint64
+	Bytes    uint64
+	Errors   uint64 // failures in writing to Transport
+
+	// Elapsed is the wall-clock time of the run, including the time waiting for
+	// the configured rate.
+	Elapsed time.Duration
+
+	// EncodeTime is the total time taken to encode the messages.
+	EncodeTime time.Duration
+	MinLatency time.Duration
+	MaxLatency time.Duration
+
+	// histogram of the encode latencies, by the bit length of nanoseconds.
+	hist [64]uint64
+}
+
+func (s *Stats) record(n int, d time.Duration) {
+	s.Messages++
+	s.Bytes += uint64(n)
+	s.EncodeTime += d
+
+	if s.Messages == 1 || d < s.MinLatency {
+		s.MinLatency = d
+	}
+	if d > s.MaxLatency {
+		s.MaxLatency = d
+	}
+
+	s.hist[bits.Len64(uint64(d))]++
+}
+
+// Throughput returns the number of messages encoded per second of EncodeTime.
+func (s *Stats) Throughput() float64 {
+	if s.EncodeTime <= 0 {
+		return 0
+	}
+	return float64(s.Messages) / s.EncodeTime.Seconds()
+}
+
+// Rate returns the number of messages generated per second of Elapsed.
+func (s *Stats) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Messages) / s.Elapsed.Seconds()
+}
+
+// MeanLatency returns the mean of the encode latencies.
+func (s *Stats) MeanLatency() time.Duration {
+	if s.Messages == 0 {
+		return 0
+	}
+	return s.EncodeTime / time.Duration(s.Messages)
+}
+
+// Percentile returns the approximate encode latency at the given percentile (0-100).
+//
+// The latencies are recorded in power-of-two buckets, so the returned value is the
+// upper bound of the bucket, capped by MaxLatency.
+func (s *Stats) Percentile(p float64) time.Duration {
+	if s.Messages == 0 {
+		return 0
+	}
+
+	rank := uint64(p / 100 * float64(s.Messages))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var n uint64
+	for i, c := range s.hist {
+		n += c
+		if n < rank {
+			continue
+		}
+
+		upper := time.Duration(1)<<i - 1
+		if upper > s.MaxLatency {
+			return s.MaxLatency
+		}
+		return upper
+	}
+
+	return s.MaxLatency
+}
+
+// String returns the Stats in human readable format.
+func (s *Stats) String() string {
+	return fmt.Sprintf("%d messages (%d bytes, %d errors) in %s (%.0f msg/s), encode: %.0f msg/s, latency min/mean/p99/max: %s/%s/%s/%s",
+		s.Messages, s.Bytes, s.Errors, s.Elapsed, s.Rate(),
+		s.Throughput(), s.MinLatency, s.MeanLatency(), s.Percentile(99), s.MaxLatency,
+	)
+}
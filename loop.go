@@ -0,0 +1,188 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// DefaultLoopWindow is the default period in which the same segment is counted.
+const DefaultLoopWindow = 10 * time.Second
+
+// DefaultLoopThreshold is the default number of times the same segment is seen
+// before it is flagged as looping.
+const DefaultLoopThreshold = 2
+
+// DefaultMaxLoopEntries is the default number of the segments tracked at the same time.
+const DefaultMaxLoopEntries = 65536
+
+// LoopKey identifies a segment of a relayed segmented message.
+type LoopKey struct {
+	LocalReference    uint32
+	RemainingSegments uint8
+	FirstSegment      bool
+	CalledGT          string
+	CallingGT         string
+	TranslationType   params.TranslationType
+}
+
+// String returns the LoopKey in human readable format.
+func (k LoopKey) String() string {
+	return fmt.Sprintf("{SLR: %#06x, RemainingSegments: %d, CdGT: %s, CgGT: %s, TT: %d}",
+		k.LocalReference, k.RemainingSegments, k.CalledGT, k.CallingGT, k.TranslationType,
+	)
+}
+
+type loopEntry struct {
+	firstSeen time.Time
+	count     int
+}
+
+// LoopDetector detects the probable relay loops of the segmented messages.
+//
+// Every segment of a segmented message is expected to be relayed only once. The
+// LoopDetector tracks the segments (identified by LoopKey) relayed within Window,
+// and flags a loop when the same segment is seen Threshold times.
+//
+// The zero value uses DefaultLoopWindow, DefaultLoopThreshold and
+// DefaultMaxLoopEntries.
+//
+// LoopDetector is safe for concurrent use.
+type LoopDetector struct {
+	mu      sync.Mutex
+	entries map[LoopKey]*loopEntry
+
+	// Configuration
+	Window     time.Duration // DefaultLoopWindow if not positive
+	Threshold  int           // DefaultLoopThreshold if not positive
+	MaxEntries int           // DefaultMaxLoopEntries if not positive
+
+	// Callbacks
+	OnLoop func(m Message, key LoopKey, count int)
+}
+
+// NewLoopDetector creates a new LoopDetector.
+func NewLoopDetector() *LoopDetector {
+	return &LoopDetector{
+		entries:    make(map[LoopKey]*loopEntry),
+		Window:     DefaultLoopWindow,
+		Threshold:  DefaultLoopThreshold,
+		MaxEntries: DefaultMaxLoopEntries,
+	}
+}
+
+// Check records the given message and reports whether it is looping.
+// Messages other than segmented XUDT are never considered looping.
+func (d *LoopDetector) Check(m Message) bool {
+	key, ok := loopKey(m)
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+
+	d.mu.Lock()
+	if d.entries == nil {
+		d.entries = make(map[LoopKey]*loopEntry)
+	}
+
+	e, ok := d.entries[key]
+	if ok && now.Sub(e.firstSeen) > d.window() {
+		ok = false
+	}
+	if !ok {
+		d.evict(now)
+		e = &loopEntry{firstSeen: now}
+		d.entries[key] = e
+	}
+	e.count++
+	count := e.count
+	d.mu.Unlock()
+
+	threshold := d.Threshold
+	if threshold <= 0 {
+		threshold = DefaultLoopThreshold
+	}
+	if count < threshold {
+		return false
+	}
+
+	logf("Probable loop detected: %s seen %d times", key, count)
+	if d.OnLoop != nil {
+		d.OnLoop(m, key, count)
+	}
+	return true
+}
+
+// Len returns the number of the segments currently tracked.
+func (d *LoopDetector) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.entries)
+}
+
+// evict removes the expired entries when the LoopDetector is full, and the oldest
+// one if it is still full. It should be called with the mutex held.
+func (d *LoopDetector) evict(now time.Time) {
+	limit := d.MaxEntries
+	if limit <= 0 {
+		limit = DefaultMaxLoopEntries
+	}
+	if len(d.entries) < limit {
+		return
+	}
+
+	window := d.window()
+	var oldestKey LoopKey
+	var oldest time.Time
+	for k, e := range d.entries {
+		if now.Sub(e.firstSeen) > window {
+			delete(d.entries, k)
+			continue
+		}
+		if oldest.IsZero() || e.firstSeen.Before(oldest) {
+			oldestKey, oldest = k, e.firstSeen
+		}
+	}
+
+	if len(d.entries) >= limit {
+		delete(d.entries, oldestKey)
+	}
+}
+
+// window returns the Window, or DefaultLoopWindow if it is not positive.
+func (d *LoopDetector) window() time.Duration {
+	if d.Window <= 0 {
+		return DefaultLoopWindow
+	}
+	return d.Window
+}
+
+func loopKey(m Message) (LoopKey, bool) {
+	x, ok := m.(*XUDT)
+	if !ok || x.Segmentation == nil {
+		return LoopKey{}, false
+	}
+
+	key := LoopKey{
+		LocalReference:    x.Segmentation.LocalReference,
+		RemainingSegments: x.Segmentation.RemainingSegments,
+		FirstSegment:      x.Segmentation.FirstSegment,
+	}
+	if cdpa := x.CalledPartyAddress; cdpa != nil && cdpa.GlobalTitle != nil {
+		key.CalledGT = cdpa.Digits()
+		key.TranslationType = cdpa.TranslationType
+	}
+	if cgpa := x.CallingPartyAddress; cgpa != nil && cgpa.GlobalTitle != nil {
+		key.CallingGT = cgpa.Digits()
+	}
+
+	return key, true
+}
@@ -0,0 +1,232 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cgngc/go-sccp/utils"
+)
+
+// The helpers in this file manipulate the digits in AddressInformation of a GlobalTitle,
+// as it is done in the Global Title Translation.
+//
+// All of them keep the odd/even indicator consistent with the resulting digits, but
+// do not know about the parent PartyAddress. When the GlobalTitle is a part of a
+// PartyAddress, call SetLength on it after the manipulation.
+
+// DigitRule is a rule to rewrite the leading digits of a GlobalTitle.
+type DigitRule struct {
+	// Prefix is the leading digits to be matched. Empty Prefix matches any digits.
+	Prefix string
+	// Replacement is the digits to replace the matched Prefix with.
+	// Empty Replacement just strips the Prefix.
+	Replacement string
+}
+
+// NewDigitRule creates a new DigitRule.
+func NewDigitRule(prefix, replacement string) DigitRule {
+	return DigitRule{Prefix: prefix, Replacement: replacement}
+}
+
+// Matches reports whether the given digits match the DigitRule.
+func (r DigitRule) Matches(digits string) bool {
+	return strings.HasPrefix(digits, r.Prefix)
+}
+
+// Apply returns the digits rewritten by the DigitRule and whether the rule matched.
+func (r DigitRule) Apply(digits string) (string, bool) {
+	if !r.Matches(digits) {
+		return digits, false
+	}
+	return r.Replacement + digits[len(r.Prefix):], true
+}
+
+// String returns the DigitRule in a human-readable format.
+func (r DigitRule) String() string {
+	return fmt.Sprintf("%s -> %s", r.Prefix, r.Replacement)
+}
+
+// Digits returns the AddressInformation as a string of digits.
+//
+// Unlike Address, it returns an empty string for a GlobalTitle without any digits
+// instead of panicking.
+func (g *GlobalTitle) Digits() string {
+	if g == nil || len(g.AddressInformation) == 0 {
+		return ""
+	}
+	return utils.BCDDecode(g.IsOddDigits(), g.AddressInformation)
+}
+
+// SetDigits sets the given digits to AddressInformation in BCD.
+//
+// The odd/even indicator (EncodingScheme, or the top bit of NatureOfAddressIndicator
+// when GTI is GTINAIOnly) is updated to match the number of digits. The digits can
+// contain hex characters (e.g., "b" and "c" for "*" and "#").
+func (g *GlobalTitle) SetDigits(digits string) error {
+	if g == nil {
+		return fmt.Errorf("cannot set digits to nil GlobalTitle")
+	}
+
+	b, err := utils.BCDEncode(digits)
+	if err != nil {
+		return fmt.Errorf("invalid digits %q: %w", digits, err)
+	}
+	if len(b) == 0 {
+		b = nil
+	}
+
+	odd := len(digits)%2 == 1
+	switch g.GTI {
+	case GTINAIOnly:
+		if odd {
+			g.NatureOfAddressIndicator = g.NatureOfAddressIndicator.Odd()
+		} else {
+			g.NatureOfAddressIndicator = g.NatureOfAddressIndicator.Even()
+		}
+	case GTITTNPES, GTITTNPESNAI:
+		if g.EncodingScheme == ESBCDOdd || g.EncodingScheme == ESBCDEven || g.EncodingScheme == ESUnknown {
+			if odd {
+				g.EncodingScheme = ESBCDOdd
+			} else {
+				g.EncodingScheme = ESBCDEven
+			}
+		}
+	}
+
+	g.AddressInformation = b
+	return nil
+}
+
+// HasPrefix reports whether the digits in GlobalTitle begin with prefix.
+func (g *GlobalTitle) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(g.Digits(), prefix)
+}
+
+// AddPrefix prepends the given prefix to the digits.
+func (g *GlobalTitle) AddPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	return g.SetDigits(prefix + g.Digits())
+}
+
+// StripPrefix removes the given prefix from the digits.
+// It reports whether the prefix was found and removed.
+func (g *GlobalTitle) StripPrefix(prefix string) (bool, error) {
+	return g.ApplyDigitRule(NewDigitRule(prefix, ""))
+}
+
+// StripDigits removes the first n digits.
+func (g *GlobalTitle) StripDigits(n int) error {
+	digits := g.Digits()
+	if n < 0 || n > len(digits) {
+		return fmt.Errorf("cannot strip %d digits from %q", n, digits)
+	}
+	return g.SetDigits(digits[n:])
+}
+
+// ApplyDigitRule rewrites the digits with the given DigitRule.
+// It reports whether the rule matched.
+func (g *GlobalTitle) ApplyDigitRule(r DigitRule) (bool, error) {
+	digits, ok := r.Apply(g.Digits())
+	if !ok {
+		return false, nil
+	}
+
+	if err := g.SetDigits(digits); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ApplyDigitRules rewrites the digits with the first matching DigitRule in the given
+// order. It reports whether any of the rules matched.
+func (g *GlobalTitle) ApplyDigitRules(rules ...DigitRule) (bool, error) {
+	for _, r := range rules {
+		if r.Matches(g.Digits()) {
+			return g.ApplyDigitRule(r)
+		}
+	}
+	return false, nil
+}
+
+// HasTranslationType reports whether the GlobalTitle carries a TranslationType.
+func (g *GlobalTitle) HasTranslationType() bool {
+	switch g.GTI {
+	case GTITTOnly, GTITTNPES, GTITTNPESNAI:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasNatureOfAddressIndicator reports whether the GlobalTitle carries a NatureOfAddressIndicator.
+func (g *GlobalTitle) HasNatureOfAddressIndicator() bool {
+	return g.GTI == GTINAIOnly || g.GTI == GTITTNPESNAI
+}
+
+// SetTranslationType rewrites the TranslationType.
+// It fails if GTI does not have a TranslationType.
+func (g *GlobalTitle) SetTranslationType(tt TranslationType) error {
+	if !g.HasTranslationType() {
+		return fmt.Errorf("GTI %s does not have translation type", g.GTI)
+	}
+	g.TranslationType = tt
+	return nil
+}
+
+// Nature returns the NatureOfAddressIndicator without the odd/even indicator.
+func (g *GlobalTitle) Nature() NatureOfAddressIndicator {
+	return g.NatureOfAddressIndicator.Even()
+}
+
+// SetNature sets the NatureOfAddressIndicator, keeping the odd/even indicator as it is.
+func (g *GlobalTitle) SetNature(nai NatureOfAddressIndicator) error {
+	if !g.HasNatureOfAddressIndicator() {
+		return fmt.Errorf("GTI %s does not have nature of address indicator", g.GTI)
+	}
+	g.NatureOfAddressIndicator = g.NatureOfAddressIndicator&0b10000000 | nai.Even()
+	return nil
+}
+
+// ToInternational normalizes the national significant number into the international
+// number by prepending the given country code. It does nothing if NatureOfAddressIndicator
+// is not national significant number, and reports whether it was converted.
+func (g *GlobalTitle) ToInternational(cc string) (bool, error) {
+	if !g.HasNatureOfAddressIndicator() || g.Nature() != NAINationalSignificantNumber {
+		return false, nil
+	}
+
+	if err := g.AddPrefix(cc); err != nil {
+		return false, err
+	}
+	if err := g.SetNature(NAIInternationalNumber); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ToNational normalizes the international number that begins with the given country
+// code into the national significant number by stripping the country code. It does
+// nothing if NatureOfAddressIndicator is not international number or the digits are
+// from another country, and reports whether it was converted.
+func (g *GlobalTitle) ToNational(cc string) (bool, error) {
+	if !g.HasNatureOfAddressIndicator() || g.Nature() != NAIInternationalNumber {
+		return false, nil
+	}
+	if !g.HasPrefix(cc) {
+		return false, nil
+	}
+
+	if _, err := g.StripPrefix(cc); err != nil {
+		return false, err
+	}
+	if err := g.SetNature(NAINationalSignificantNumber); err != nil {
+		return false, err
+	}
+	return true, nil
+}
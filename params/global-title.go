@@ -149,7 +149,7 @@ func (g *GlobalTitle) Write(b []byte) (int, error) {
 	}
 
 	copy(b[n:l], g.AddressInformation)
-	return n, nil
+	return l, nil
 }
 
 // MarshalBinary returns the byte sequence generated from a GlobalTitle.
@@ -279,7 +279,13 @@ func (g *GlobalTitle) lenByGTI() int {
 }
 
 // IsOddDigits reports whether AddressInformation is odd number or not.
+//
+// When GTI is GTINAIOnly, it is indicated by the odd/even indicator in
+// NatureOfAddressIndicator, as there is no EncodingScheme.
 func (g *GlobalTitle) IsOddDigits() bool {
+	if g.GTI == GTINAIOnly {
+		return g.NatureOfAddressIndicator&0b10000000 != 0
+	}
 	return g.EncodingScheme == ESBCDOdd
 }
 
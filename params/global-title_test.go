@@ -0,0 +1,141 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+package params_test
+
+import (
+	"testing"
+
+	"github.com/cgngc/go-sccp/params"
+	"github.com/cgngc/go-sccp/utils"
+)
+
+func newGT(nai params.NatureOfAddressIndicator, digits string) *params.GlobalTitle {
+	gt := params.NewGlobalTitle(
+		params.GTITTNPESNAI,
+		params.TranslationType(0),
+		params.NPISDNTelephony,
+		params.ESBCDEven,
+		nai,
+		nil,
+	)
+	if err := gt.SetDigits(digits); err != nil {
+		panic(err)
+	}
+	return gt
+}
+
+func TestGlobalTitleDigits(t *testing.T) {
+	cases := []struct {
+		description string
+		gt          *params.GlobalTitle
+		manipulate  func(*params.GlobalTitle) error
+		wantDigits  string
+		wantNAI     params.NatureOfAddressIndicator
+	}{
+		{
+			"AddPrefix",
+			newGT(params.NAIInternationalNumber, "1234"),
+			func(g *params.GlobalTitle) error { return g.AddPrefix("9") },
+			"91234", params.NAIInternationalNumber,
+		}, {
+			"StripPrefix",
+			newGT(params.NAIInternationalNumber, "0012345"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.StripPrefix("00")
+				return err
+			},
+			"12345", params.NAIInternationalNumber,
+		}, {
+			"StripPrefix/Not matched",
+			newGT(params.NAIInternationalNumber, "12345"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.StripPrefix("00")
+				return err
+			},
+			"12345", params.NAIInternationalNumber,
+		}, {
+			"ApplyDigitRules/First match",
+			newGT(params.NAIInternationalNumber, "4477001122"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.ApplyDigitRules(
+					params.NewDigitRule("33", "0"),
+					params.NewDigitRule("4477", "4479"),
+					params.NewDigitRule("44", ""),
+				)
+				return err
+			},
+			"4479001122", params.NAIInternationalNumber,
+		}, {
+			"ToInternational",
+			newGT(params.NAINationalSignificantNumber, "7700112233"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.ToInternational("44")
+				return err
+			},
+			"447700112233", params.NAIInternationalNumber,
+		}, {
+			"ToNational",
+			newGT(params.NAIInternationalNumber, "447700112233"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.ToNational("44")
+				return err
+			},
+			"7700112233", params.NAINationalSignificantNumber,
+		}, {
+			"ToNational/Other country",
+			newGT(params.NAIInternationalNumber, "33612345678"),
+			func(g *params.GlobalTitle) error {
+				_, err := g.ToNational("44")
+				return err
+			},
+			"33612345678", params.NAIInternationalNumber,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if err := c.manipulate(c.gt); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.gt.Digits(); got != c.wantDigits {
+				t.Errorf("digits: got %s, want %s", got, c.wantDigits)
+			}
+			if got := c.gt.Nature(); got != c.wantNAI {
+				t.Errorf("NAI: got %s, want %s", got, c.wantNAI)
+			}
+
+			wantES := params.ESBCDEven
+			if len(c.wantDigits)%2 == 1 {
+				wantES = params.ESBCDOdd
+			}
+			if got := c.gt.EncodingScheme; got != wantES {
+				t.Errorf("ES: got %s, want %s", got, wantES)
+			}
+		})
+	}
+}
+
+func TestGlobalTitleNAIOnlyOddEven(t *testing.T) {
+	gt := params.NewGlobalTitle(
+		params.GTINAIOnly, 0, 0, 0,
+		params.NAIInternationalNumber.Odd(),
+		utils.MustBCDEncode("12345"),
+	)
+
+	if got, want := gt.Digits(), "12345"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if _, err := gt.StripPrefix("1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gt.NatureOfAddressIndicator, params.NAIInternationalNumber.Even(); got != want {
+		t.Errorf("got %#08b, want %#08b", got, want)
+	}
+
+	if err := gt.SetTranslationType(1); err == nil {
+		t.Error("got no error setting TT to GTI without TT")
+	}
+}
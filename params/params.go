@@ -352,7 +352,7 @@ type PartyAddress struct {
 	length    int
 
 	Indicator          uint8
-	SignalingPointCode uint16
+	SignalingPointCode PointCode
 	SubsystemNumber    uint8
 	*GlobalTitle
 }
@@ -386,7 +386,7 @@ func NewAddressIndicator(hasPC, hasSSN, routeOnSSN bool, gti GlobalTitleIndicato
 // When you are aware of the type of PartyAddress you are creating, you can use
 // NewCalled/CallingPartyAddress to create a PartyAddress with the correct code.
 // Otherwise, you can use AsCalled/Calling to set the code after creating a PartyAddress.
-func NewPartyAddress(cdcg ParameterNameCode, ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewPartyAddress(cdcg ParameterNameCode, ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	if cdcg != PCodeCalledPartyAddress && cdcg != PCodeCallingPartyAddress {
 		logf("invalid parameter code: expected %v or %v, got %v", PCodeCalledPartyAddress, PCodeCallingPartyAddress, cdcg)
 	}
@@ -411,29 +411,29 @@ func NewPartyAddress(cdcg ParameterNameCode, ai uint8, spc uint16, ssn uint8, gt
 }
 
 // NewPartyAddressOptional creates a new PartyAddress from properly-typed values.
-func NewPartyAddressOptional(cdcg ParameterNameCode, ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewPartyAddressOptional(cdcg ParameterNameCode, ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	p := NewPartyAddress(cdcg, ai, spc, ssn, gt)
 	p.paramType = PTypeO
 	return p
 }
 
 // NewCalledPartyAddress creates a new PartyAddress for Called Party Address.
-func NewCalledPartyAddress(ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewCalledPartyAddress(ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	return NewPartyAddress(PCodeCalledPartyAddress, ai, spc, ssn, gt)
 }
 
 // NewCallingPartyAddress creates a new PartyAddress for Calling Party Address.
-func NewCallingPartyAddress(ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewCallingPartyAddress(ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	return NewPartyAddress(PCodeCallingPartyAddress, ai, spc, ssn, gt)
 }
 
 // NewCalledPartyAddressOptional creates a new PartyAddress for Called Party Address as an optional parameter.
-func NewCalledPartyAddressOptional(ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewCalledPartyAddressOptional(ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	return NewPartyAddressOptional(PCodeCalledPartyAddress, ai, spc, ssn, gt)
 }
 
 // NewCallingPartyAddressOptional creates a new PartyAddress for Calling Party Address as an optional parameter.
-func NewCallingPartyAddressOptional(ai uint8, spc uint16, ssn uint8, gt *GlobalTitle) *PartyAddress {
+func NewCallingPartyAddressOptional(ai uint8, spc PointCode, ssn uint8, gt *GlobalTitle) *PartyAddress {
 	return NewPartyAddressOptional(PCodeCallingPartyAddress, ai, spc, ssn, gt)
 }
 
@@ -501,10 +501,10 @@ func (p *PartyAddress) read(b []byte) (int, error) {
 
 	if p.HasPC() {
 		end := n + 2
-		if end >= len(b) {
+		if end > len(b) {
 			return n, io.ErrUnexpectedEOF
 		}
-		p.SignalingPointCode = binary.LittleEndian.Uint16(b[n:end])
+		p.SignalingPointCode = PointCode(binary.LittleEndian.Uint16(b[n:end]))
 		n = end
 	}
 
@@ -596,7 +596,10 @@ func (p *PartyAddress) write(b []byte) (int, error) {
 
 	var n = 2
 	if p.HasPC() {
-		binary.LittleEndian.PutUint16(b[n:n+2], p.SignalingPointCode)
+		if p.SignalingPointCode > MaxITUPointCode {
+			return n, fmt.Errorf("point code %s does not fit in 14 bits", p.SignalingPointCode)
+		}
+		binary.LittleEndian.PutUint16(b[n:n+2], p.SignalingPointCode.Uint16())
 		n += 2
 	}
 
@@ -741,8 +744,8 @@ func (p *PartyAddress) Value() *PartyAddress {
 
 // String returns the PartyAddress values in human readable format.
 func (p *PartyAddress) String() string {
-	return fmt.Sprintf("{%s (%s): {length: %d, Indicator: %#08b, SignalingPointCode: %d, SubsystemNumber: %d, GlobalTitle: %v}}",
-		p.code, p.paramType, p.length, p.Indicator, p.GetRoutingType(), p.SignalingPointCode, p.SubsystemNumber, p.GlobalTitle, p.Address(),
+	return fmt.Sprintf("{%s (%s): {length: %d, Indicator: %#08b, SignalingPointCode: %s, SubsystemNumber: %d, GlobalTitle: %v}}",
+		p.code, p.paramType, p.length, p.Indicator, p.SignalingPointCode, p.SubsystemNumber, p.GlobalTitle,
 	)
 }
 
@@ -786,11 +789,11 @@ func (p *PartyAddress) Address() string {
 	if p.RouteOnSSN() {
 		// Route on SSN - return point code and subsystem number
 		if p.HasPC() && p.HasSSN() {
-			return fmt.Sprintf("PC:%d,SSN:%d", p.SignalingPointCode, p.SubsystemNumber)
+			return fmt.Sprintf("PC:%s,SSN:%d", p.SignalingPointCode, p.SubsystemNumber)
 		} else if p.HasSSN() {
 			return fmt.Sprintf("SSN:%d", p.SubsystemNumber)
 		} else if p.HasPC() {
-			return fmt.Sprintf("PC:%d", p.SignalingPointCode)
+			return fmt.Sprintf("PC:%s", p.SignalingPointCode)
 		}
 		return "SSN_ROUTING"
 	} else {
@@ -818,7 +821,7 @@ func (p *PartyAddress) AddressWithDetails() string {
 
 	// Add point code if present
 	if p.HasPC() {
-		parts = append(parts, fmt.Sprintf("PC:%d", p.SignalingPointCode))
+		parts = append(parts, fmt.Sprintf("PC:%s", p.SignalingPointCode))
 	}
 
 	// Add subsystem number if present
@@ -1336,7 +1339,7 @@ func (c *Credit) write(b []byte) (int, error) {
 }
 
 func (c *Credit) writeOptional(b []byte) (int, error) {
-	if len(b) < c.length {
+	if len(b) < c.length+2 {
 		return 0, io.ErrUnexpectedEOF
 	}
 
@@ -1344,7 +1347,7 @@ func (c *Credit) writeOptional(b []byte) (int, error) {
 	b[1] = uint8(c.length)
 	b[2] = c.value
 
-	return c.length, nil
+	return c.length + 2, nil
 }
 
 // MarshalLen returns the serial length of Credit.
@@ -1640,33 +1643,89 @@ func ParseRefusalCause(b []byte) (*RefusalCause, int, error) {
 }
 
 // Data represents the Data.
+//
+// Data either refers to the byte sequence it is created from or parsed from as it is
+// (DataModeZeroCopy, the default), or holds its own copy of it (DataModeOwned).
+// Use the zero-copy mode only when the underlying buffer outlives the Data and is not
+// modified or reused (e.g., returned to a pool) while the Data is in use.
 type Data struct {
 	paramType ParameterType
 	code      ParameterNameCode
 	length    int
 	value     []byte
+	mode      DataMode
+}
+
+// DataMode is a mode that determines how Data holds its value.
+type DataMode uint8
+
+// DataMode values.
+const (
+	// DataModeZeroCopy makes Data refer to the given byte sequence without copying.
+	DataModeZeroCopy DataMode = iota
+	// DataModeOwned makes Data hold its own copy of the given byte sequence.
+	DataModeOwned
+)
+
+// String returns the DataMode in string.
+func (m DataMode) String() string {
+	switch m {
+	case DataModeZeroCopy:
+		return "zero-copy"
+	case DataModeOwned:
+		return "owned"
+	default:
+		return fmt.Sprintf("DataMode(%d)", m)
+	}
+}
+
+// DataOption is an option to configure Data on creation and parsing.
+type DataOption func(*Data)
+
+// WithDataMode sets the DataMode of Data.
+func WithDataMode(m DataMode) DataOption {
+	return func(d *Data) {
+		d.mode = m
+	}
+}
+
+// WithOwnedData is a shorthand for WithDataMode(DataModeOwned).
+func WithOwnedData() DataOption {
+	return WithDataMode(DataModeOwned)
 }
 
 // NewData creates a new Data.
-func NewData(v []byte) *Data {
-	return &Data{
+//
+// By default, the Data refers to v without copying. Give WithOwnedData to make a copy.
+func NewData(v []byte, opts ...DataOption) *Data {
+	d := &Data{
 		paramType: PTypeV,
 		code:      PCodeData,
-		length:    len(v),
-		value:     v,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.setValue(v)
+	return d
 }
 
 // NewDataOptional creates a new Data as an optional parameter.
-func NewDataOptional(v []byte) *Data {
-	d := NewData(v)
+func NewDataOptional(v []byte, opts ...DataOption) *Data {
+	d := NewData(v, opts...)
 	d.paramType = PTypeO
 	return d
 }
 
 // ParseData parses the given byte sequence as a Data.
-func ParseData(b []byte) (*Data, int, error) {
+//
+// By default, the Data refers to b without copying. Give WithOwnedData to make a copy.
+func ParseData(b []byte, opts ...DataOption) (*Data, int, error) {
 	d := &Data{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
 	n, err := d.Read(b)
 	if err != nil {
 		return nil, n, err
@@ -1676,8 +1735,13 @@ func ParseData(b []byte) (*Data, int, error) {
 }
 
 // ParseDataOptional parses the given byte sequence as an optional Data.
-func ParseDataOptional(b []byte) (*Data, int, error) {
-	d := &Data{paramType: PTypeO}
+func ParseDataOptional(b []byte, opts ...DataOption) (*Data, int, error) {
+	d := &Data{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.paramType = PTypeO
+
 	n, err := d.Read(b)
 	if err != nil {
 		return nil, n, err
@@ -1687,6 +1751,8 @@ func ParseDataOptional(b []byte) (*Data, int, error) {
 }
 
 // Read sets the values retrieved from byte sequence in a Data.
+//
+// The value is copied only if the Data is in DataModeOwned.
 func (d *Data) Read(b []byte) (int, error) {
 	if d.paramType == PTypeO {
 		return d.readOptional(b)
@@ -1707,36 +1773,45 @@ func (d *Data) read(b []byte) (int, error) {
 	}
 
 	d.code = PCodeData
-	d.length = int(b[0])
-	if d.length == 0 {
-		d.value = nil
-		return 1, nil
-	}
-
-	if n < d.length+1 {
+	l := int(b[0])
+	if n < l+1 {
 		return 1, io.ErrUnexpectedEOF
 	}
 
-	d.value = b[1 : d.length+1]
-
-	return n, nil
+	d.setValue(b[1 : l+1])
+	return l + 1, nil
 }
 
 func (d *Data) readOptional(b []byte) (int, error) {
-	n := len(b)
+	if len(b) < 1 {
+		return 0, io.ErrUnexpectedEOF
+	}
 
 	d.code = ParameterNameCode(b[0])
 	if d.code != PCodeData {
 		logf("invalid parameter code: expected %d, got %d", PCodeData, d.code)
 	}
 
-	m, err := d.read(b[1:])
-	n += m
+	n, err := d.read(b[1:])
 	if err != nil {
-		return n, err
+		return n + 1, err
 	}
 
-	return n, nil
+	return n + 1, nil
+}
+
+func (d *Data) setValue(v []byte) {
+	d.length = len(v)
+	if d.length == 0 {
+		d.value = nil
+		return
+	}
+
+	if d.mode == DataModeOwned {
+		d.value = append(make([]byte, 0, d.length), v...)
+		return
+	}
+	d.value = v
 }
 
 // Write serializes the Data parameter and returns it as a byte slice.
@@ -1749,6 +1824,9 @@ func (d *Data) Write(b []byte) (int, error) {
 
 // write serializes the Data parameter and returns it as a byte slice.
 func (d *Data) write(b []byte) (int, error) {
+	if d.length > 0xff {
+		return 0, fmt.Errorf("data too long: %d octets, max %d", d.length, 0xff)
+	}
 	if len(b) < d.length+1 {
 		return 0, io.ErrUnexpectedEOF
 	}
@@ -1759,18 +1837,21 @@ func (d *Data) write(b []byte) (int, error) {
 	}
 
 	copy(b[1:d.length+1], d.value)
-	return d.length, nil
+	return d.length + 1, nil
 }
 
 func (d *Data) writeOptional(b []byte) (int, error) {
-	if len(b) < d.length {
+	if len(b) < d.length+2 {
 		return 0, io.ErrUnexpectedEOF
 	}
 
 	b[0] = uint8(d.code)
-	b[1] = uint8(d.length)
-	copy(b[2:], d.value)
-	return d.length, nil
+	n, err := d.write(b[1:])
+	if err != nil {
+		return n + 1, err
+	}
+
+	return n + 1, nil
 }
 
 // MarshalLen returns the serial length of Data.
@@ -1787,10 +1868,70 @@ func (d *Data) Code() ParameterNameCode {
 }
 
 // Value returns the Data in []byte.
+//
+// Value is the same as Bytes; see it for the aliasing rules.
 func (d *Data) Value() []byte {
+	return d.Bytes()
+}
+
+// Bytes returns the value of Data without copying.
+//
+// In DataModeZeroCopy, the returned slice shares the memory with the buffer the Data
+// is created from. Use AppendTo to retrieve a copy that is safe to retain.
+func (d *Data) Bytes() []byte {
+	if d == nil {
+		return nil
+	}
 	return d.value
 }
 
+// Len returns the length of the value of Data.
+func (d *Data) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.value)
+}
+
+// AppendTo appends the value of Data to b and returns the extended slice.
+func (d *Data) AppendTo(b []byte) []byte {
+	return append(b, d.Bytes()...)
+}
+
+// Mode returns the DataMode of Data.
+func (d *Data) Mode() DataMode {
+	return d.mode
+}
+
+// Own makes the Data hold its own copy of the value, so that it is no longer affected
+// by the changes in the buffer it is created from. It does nothing if the Data is
+// already in DataModeOwned.
+func (d *Data) Own() {
+	if d == nil || d.mode == DataModeOwned {
+		return
+	}
+
+	d.mode = DataModeOwned
+	d.setValue(d.value)
+}
+
+// Apply applies opts to the Data created or parsed already. The value is copied if
+// the Data is turned into DataModeOwned, as with Own.
+func (d *Data) Apply(opts ...DataOption) {
+	if d == nil {
+		return
+	}
+
+	mode := d.mode
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.mode == DataModeOwned && mode != DataModeOwned {
+		d.mode = mode
+		d.Own()
+	}
+}
+
 // String returns the Data in string.
 func (d *Data) String() string {
 	return fmt.Sprintf("{%s (%s): %x}", d.code, d.paramType, d.value)
@@ -1884,13 +2025,11 @@ func (s *Segmentation) Write(b []byte) (int, error) {
 	b[0] = uint8(s.code)
 	b[1] = uint8(s.length)
 
+	b[2] = s.Class&0b1<<6 | s.RemainingSegments&0b1111
 	if s.FirstSegment {
 		b[2] |= 0b10000000
 	}
 
-	b[2] |= s.Class & 0b1 << 6
-	b[2] |= s.RemainingSegments & 0b111
-
 	copy(b[3:], utils.Uint32To24(s.LocalReference))
 
 	return n, nil
@@ -2027,7 +2166,7 @@ func (h *HopCounter) write(b []byte) (int, error) {
 }
 
 func (h *HopCounter) writeOptional(b []byte) (int, error) {
-	if len(b) < h.length {
+	if len(b) < h.length+2 {
 		return 0, io.ErrUnexpectedEOF
 	}
 
@@ -2035,7 +2174,7 @@ func (h *HopCounter) writeOptional(b []byte) (int, error) {
 	b[1] = uint8(h.length)
 	b[2] = h.value
 
-	return h.length, nil
+	return h.length + 2, nil
 }
 
 // MarshalLen returns the serial length of HopCounter.
@@ -2056,6 +2195,15 @@ func (h *HopCounter) Value() uint8 {
 	return h.value
 }
 
+// Decrement decrements the HopCounter by one and returns the new value.
+// The value stays 0 once it reaches 0, which indicates a hop counter violation.
+func (h *HopCounter) Decrement() uint8 {
+	if h.value > 0 {
+		h.value--
+	}
+	return h.value
+}
+
 // String returns the HopCounter in string.
 func (h *HopCounter) String() string {
 	return fmt.Sprintf("{%s (%s): %d}", h.code, h.paramType, h.value)
@@ -2164,6 +2312,9 @@ func (i *Importance) String() string {
 	return fmt.Sprintf("{%s (%s): %d}", i.code, i.paramType, i.value)
 }
 
+// MaxLongDataLength is the maximum length of the value of LongData.
+const MaxLongDataLength = 3952
+
 // LongData represents the Long Data.
 type LongData struct {
 	paramType ParameterType
@@ -2195,29 +2346,35 @@ func ParseLongData(b []byte) (*LongData, int, error) {
 
 // Read sets the values retrieved from byte sequence in a LongData.
 func (l *LongData) Read(b []byte) (int, error) {
-	n := len(b)
+	if len(b) < 2 {
+		return 0, io.ErrUnexpectedEOF
+	}
 
 	l.paramType = PTypeV
 	l.code = PCodeLongData
 
 	l.length = int(binary.BigEndian.Uint16(b[:2]))
-	if n < l.length+2 {
-		return n, io.ErrUnexpectedEOF
+	n := l.length + 2
+	if len(b) < n {
+		return len(b), io.ErrUnexpectedEOF
 	}
 
-	l.value = b[2 : l.length+2]
+	l.value = b[2:n]
 	return n, nil
 }
 
 // Write serializes the LongData parameter and returns it as a byte slice.
 func (l *LongData) Write(b []byte) (int, error) {
+	if l.length > MaxLongDataLength {
+		return 0, fmt.Errorf("long data too long: %d octets, max %d", l.length, MaxLongDataLength)
+	}
 	if len(b) < l.length+2 {
 		return 0, io.ErrUnexpectedEOF
 	}
 
 	binary.BigEndian.PutUint16(b, uint16(l.length))
 	copy(b[2:], l.value)
-	return l.length, nil
+	return l.length + 2, nil
 }
 
 // MarshalLen returns the serial length of LongData.
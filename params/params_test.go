@@ -277,3 +277,63 @@ func TestParams(t *testing.T) {
 		})
 	}
 }
+
+func TestDataMode(t *testing.T) {
+	serialized := []byte{0x04, 0xde, 0xad, 0xbe, 0xef}
+
+	t.Run("ZeroCopy", func(t *testing.T) {
+		b := append([]byte{}, serialized...)
+		d, n, err := params.ParseData(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(b) {
+			t.Errorf("got %d bytes read, want %d", n, len(b))
+		}
+
+		b[1] = 0x00
+		if got, want := d.Bytes()[0], uint8(0x00); got != want {
+			t.Errorf("got %#x, want %#x: value should alias the buffer", got, want)
+		}
+	})
+
+	t.Run("Owned", func(t *testing.T) {
+		b := append([]byte{}, serialized...)
+		d, _, err := params.ParseData(b, params.WithOwnedData())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b[1] = 0x00
+		if got, want := d.Bytes(), serialized[1:]; !verify.Values(t, "", got, want) {
+			t.Errorf("got %x, want %x: value should not alias the buffer", got, want)
+		}
+	})
+
+	t.Run("Own", func(t *testing.T) {
+		b := append([]byte{}, serialized...)
+		d, _, err := params.ParseData(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d.Own()
+		b[1] = 0x00
+		if got, want := d.Bytes(), serialized[1:]; !verify.Values(t, "", got, want) {
+			t.Errorf("got %x, want %x: value should not alias the buffer", got, want)
+		}
+		if got, want := d.Mode(), params.DataModeOwned; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("AppendTo", func(t *testing.T) {
+		d := params.NewData(serialized[1:])
+		if got, want := d.Len(), 4; got != want {
+			t.Errorf("got %d, want %d", got, want)
+		}
+		if got, want := d.AppendTo([]byte{0x01}), []byte{0x01, 0xde, 0xad, 0xbe, 0xef}; !verify.Values(t, "", got, want) {
+			t.Errorf("got %x, want %x", got, want)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package params
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PointCode is a Signalling Point Code.
+//
+// It holds both ITU-T 14-bit and ANSI 24-bit point codes as a plain integer.
+// The format only matters when the point code is converted from/to a string,
+// which can be done with ParsePointCode and Format.
+type PointCode uint32
+
+// PointCodeFormat is a format of the human-readable representation of PointCode.
+type PointCodeFormat uint8
+
+// PointCodeFormat values.
+const (
+	PointCodeFormatRaw  PointCodeFormat = iota // decimal integer, e.g., "1234"
+	PointCodeFormatITU                         // 3-8-3 (zone-area-signalling point), e.g., "2-121-5"
+	PointCodeFormatANSI                        // 8-8-8 (network-cluster-member), e.g., "245-16-1"
+)
+
+// Maximum values of the point codes in each format.
+const (
+	MaxITUPointCode  PointCode = 0x3fff
+	MaxANSIPointCode PointCode = 0xffffff
+)
+
+// NewITUPointCode creates a new PointCode from ITU-T 3-8-3 components.
+// The values exceeding the bits of each component are cut off.
+func NewITUPointCode(zone, area, sp uint8) PointCode {
+	return PointCode(zone&0x07)<<11 | PointCode(area)<<3 | PointCode(sp&0x07)
+}
+
+// NewANSIPointCode creates a new PointCode from ANSI network-cluster-member components.
+func NewANSIPointCode(network, cluster, member uint8) PointCode {
+	return PointCode(network)<<16 | PointCode(cluster)<<8 | PointCode(member)
+}
+
+// ParsePointCode parses the given string as a PointCode in the specified format.
+//
+// A plain decimal integer is accepted regardless of the format, as long as the value
+// fits in the format.
+func ParsePointCode(s string, f PointCodeFormat) (PointCode, error) {
+	s = strings.TrimSpace(s)
+	max := f.max()
+
+	if !strings.Contains(s, "-") {
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid point code %q: %w", s, err)
+		}
+		if pc := PointCode(v); pc <= max {
+			return pc, nil
+		}
+		return 0, fmt.Errorf("invalid point code %q: exceeds %d in %s format", s, max, f)
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid point code %q: expected 3 components", s)
+	}
+
+	var bits [3]int
+	switch f {
+	case PointCodeFormatITU:
+		bits = [3]int{3, 8, 3}
+	case PointCodeFormatANSI:
+		bits = [3]int{8, 8, 8}
+	default:
+		return 0, fmt.Errorf("invalid point code %q: %s format does not have components", s, f)
+	}
+
+	var pc PointCode
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, bits[i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid point code %q: component %d: %w", s, i+1, err)
+		}
+		pc = pc<<bits[i] | PointCode(v)
+	}
+
+	return pc, nil
+}
+
+// MustParsePointCode is the same as ParsePointCode but panics if any error occurs.
+// Use this function only when you are sure that the input string is valid.
+func MustParsePointCode(s string, f PointCodeFormat) PointCode {
+	pc, err := ParsePointCode(s, f)
+	if err != nil {
+		panic(err)
+	}
+	return pc
+}
+
+// Format returns the PointCode in the specified format.
+func (pc PointCode) Format(f PointCodeFormat) string {
+	switch f {
+	case PointCodeFormatITU:
+		return fmt.Sprintf("%d-%d-%d", pc>>11&0x07, pc>>3&0xff, pc&0x07)
+	case PointCodeFormatANSI:
+		return fmt.Sprintf("%d-%d-%d", pc>>16&0xff, pc>>8&0xff, pc&0xff)
+	default:
+		return strconv.FormatUint(uint64(pc), 10)
+	}
+}
+
+// String returns the PointCode in a human-readable format.
+//
+// It is formatted as ITU-T 3-8-3 if the value fits in 14 bits, otherwise as ANSI
+// network-cluster-member. Use Format to choose the format explicitly.
+func (pc PointCode) String() string {
+	if pc.IsITU() {
+		return pc.Format(PointCodeFormatITU)
+	}
+	return pc.Format(PointCodeFormatANSI)
+}
+
+// IsITU reports whether the PointCode fits in ITU-T 14 bits.
+func (pc PointCode) IsITU() bool {
+	return pc <= MaxITUPointCode
+}
+
+// Uint16 returns the PointCode in uint16, which is the way ITU-T point codes are
+// carried in the SCCP messages. The values in 17-32 bit are cut off.
+func (pc PointCode) Uint16() uint16 {
+	return uint16(pc)
+}
+
+// Uint32 returns the PointCode in uint32.
+func (pc PointCode) Uint32() uint32 {
+	return uint32(pc)
+}
+
+func (f PointCodeFormat) max() PointCode {
+	if f == PointCodeFormatITU {
+		return MaxITUPointCode
+	}
+	return MaxANSIPointCode
+}
+
+// String returns the PointCodeFormat in string.
+func (f PointCodeFormat) String() string {
+	switch f {
+	case PointCodeFormatRaw:
+		return "raw"
+	case PointCodeFormatITU:
+		return "ITU"
+	case PointCodeFormatANSI:
+		return "ANSI"
+	default:
+		return "PointCodeFormat(" + strconv.Itoa(int(f)) + ")"
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+package params_test
+
+import (
+	"testing"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+func TestPointCode(t *testing.T) {
+	cases := []struct {
+		description string
+		format      params.PointCodeFormat
+		str         string
+		pc          params.PointCode
+	}{
+		{"ITU", params.PointCodeFormatITU, "2-121-5", params.NewITUPointCode(2, 121, 5)},
+		{"ITU/max", params.PointCodeFormatITU, "7-255-7", params.MaxITUPointCode},
+		{"ANSI", params.PointCodeFormatANSI, "245-16-1", params.NewANSIPointCode(245, 16, 1)},
+		{"ANSI/max", params.PointCodeFormatANSI, "255-255-255", params.MaxANSIPointCode},
+		{"Raw", params.PointCodeFormatRaw, "405", 405},
+	}
+
+	for _, c := range cases {
+		t.Run("Parse/"+c.description, func(t *testing.T) {
+			pc, err := params.ParsePointCode(c.str, c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pc != c.pc {
+				t.Errorf("got %d, want %d", pc, c.pc)
+			}
+		})
+
+		t.Run("Format/"+c.description, func(t *testing.T) {
+			if got := c.pc.Format(c.format); got != c.str {
+				t.Errorf("got %s, want %s", got, c.str)
+			}
+		})
+	}
+
+	t.Run("Parse/Raw in ITU", func(t *testing.T) {
+		pc, err := params.ParsePointCode("5069", params.PointCodeFormatITU)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := pc.String(), "2-121-5"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	for _, s := range []string{"8-0-0", "0-256-0", "16384", "1-2", "a-b-c", ""} {
+		t.Run("Parse/Invalid ITU/"+s, func(t *testing.T) {
+			if _, err := params.ParsePointCode(s, params.PointCodeFormatITU); err == nil {
+				t.Errorf("got no error for %q", s)
+			}
+		})
+	}
+}
+
+func TestPartyAddressPointCodeRange(t *testing.T) {
+	for _, pc := range []params.PointCode{params.MaxITUPointCode + 1, 0xffff, 0x10000} {
+		p := params.NewCalledPartyAddress(0x43, pc, 6, nil)
+		b := make([]byte, p.MarshalLen())
+		if _, err := p.Write(b); err == nil {
+			t.Errorf("got no error for point code %#x", uint32(pc))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package params_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func TestParamsRoundTrip(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			prm, n, err := c.parseFunc(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := n, len(c.serialized); got != want {
+				t.Errorf("got %d octets read, want %d", got, want)
+			}
+
+			// the buffer is not zeroed so that the bits left in it are caught.
+			b := bytes.Repeat([]byte{0xff}, len(c.serialized))
+			n, err = prm.Write(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := n, len(c.serialized); got != want {
+				t.Errorf("got %d octets written, want %d", got, want)
+			}
+			if got, want := b, c.serialized; !verify.Values(t, "", got, want) {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestPartyAddressRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		hasPC, hasSSN := rnd.Intn(2) == 0, rnd.Intn(2) == 0
+		gti := params.GlobalTitleIndicator(rnd.Intn(5))
+
+		var gt *params.GlobalTitle
+		if gti != params.GTINoGT {
+			digits := make([]byte, 1+rnd.Intn(10))
+			rnd.Read(digits)
+			gt = params.NewGlobalTitle(
+				gti,
+				params.TranslationType(rnd.Intn(0x100)),
+				params.NumberingPlan(rnd.Intn(0x10)),
+				params.EncodingScheme(1+rnd.Intn(2)),
+				params.NatureOfAddressIndicator(rnd.Intn(0x80)),
+				digits,
+			)
+		}
+
+		var pc params.PointCode
+		if hasPC {
+			pc = params.PointCode(rnd.Intn(0x4000))
+		}
+		var ssn uint8
+		if hasSSN {
+			ssn = uint8(1 + rnd.Intn(0xfe))
+		}
+
+		p := params.NewCalledPartyAddress(
+			params.NewAddressIndicator(hasPC, hasSSN, gti == params.GTINoGT || rnd.Intn(2) == 0, gti),
+			pc, ssn, gt,
+		)
+
+		b := make([]byte, p.MarshalLen())
+		if _, err := p.Write(b); err != nil {
+			t.Fatalf("#%d %v: %v", i, p, err)
+		}
+
+		decoded, n, err := params.ParseCalledPartyAddress(b)
+		if err != nil {
+			t.Fatalf("#%d %x: %v", i, b, err)
+		}
+		if got, want := n, len(b); got != want {
+			t.Errorf("#%d %x: got %d octets read, want %d", i, b, got, want)
+		}
+		if got, want := decoded, p; !verify.Values(t, "", got, want) {
+			t.Fatalf("#%d %x: decoded parameter differs", i, b)
+		}
+	}
+}
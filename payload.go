@@ -0,0 +1,124 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoPayloadParser indicates the payload is decoded without a PayloadParser.
+var ErrNoPayloadParser = errors.New("sccp: no payload parser")
+
+// PayloadParser is an interface to decode the payload carried in the Data of the
+// unitdata messages into the upper layer PDU, e.g., TCAP.
+//
+// The given byte sequence is the value of Data, without its length octet. It may
+// refer to the buffer the message is parsed from, so ParsePayload must copy it if
+// the PDU refers to it after returning.
+type PayloadParser interface {
+	ParsePayload(b []byte) (any, error)
+}
+
+// PayloadParserFunc is an adapter to use an ordinary function as a PayloadParser.
+type PayloadParserFunc func(b []byte) (any, error)
+
+// ParsePayload calls f(b). It returns ErrNoPayloadParser if f is nil.
+func (f PayloadParserFunc) ParsePayload(b []byte) (any, error) {
+	if f == nil {
+		return nil, ErrNoPayloadParser
+	}
+	return f(b)
+}
+
+// ParserOf returns a PayloadParser from a typed parser function, e.g., tcap.Parse
+// in github.com/wmnsk/go-tcap.
+//
+//	pdu, err := udt.DecodePayload(sccp.ParserOf(tcap.Parse))
+func ParserOf[T any](parse func(b []byte) (T, error)) PayloadParser {
+	if parse == nil {
+		return PayloadParserFunc(nil)
+	}
+	return PayloadParserFunc(func(b []byte) (any, error) {
+		return parse(b)
+	})
+}
+
+// DecodePayload decodes the payload in the given message with p.
+//
+// It returns ErrNotUnitdata if m is not a Unitdata, and ErrNoPayloadParser if p
+// is nil.
+func DecodePayload(m Message, p PayloadParser) (any, error) {
+	u, ok := m.(Unitdata)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotUnitdata, m.MessageTypeName())
+	}
+	if p == nil {
+		return nil, fmt.Errorf("failed to decode payload in %s: %w", m.MessageTypeName(), ErrNoPayloadParser)
+	}
+
+	pdu, err := p.ParsePayload(u.Payload())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload in %s: %w", m.MessageTypeName(), err)
+	}
+	return pdu, nil
+}
+
+// DecodePayload decodes the payload in UDT with p.
+func (u *UDT) DecodePayload(p PayloadParser) (any, error) {
+	return DecodePayload(u, p)
+}
+
+// DecodePayload decodes the payload in XUDT with p.
+func (x *XUDT) DecodePayload(p PayloadParser) (any, error) {
+	return DecodePayload(x, p)
+}
+
+// DecodePayload decodes the payload in UDTS with p.
+func (u *UDTS) DecodePayload(p PayloadParser) (any, error) {
+	return DecodePayload(u, p)
+}
+
+// DecodePayload decodes the payload in XUDTS with p.
+func (x *XUDTS) DecodePayload(p PayloadParser) (any, error) {
+	return DecodePayload(x, p)
+}
+
+// PayloadHandler decodes the payload in the received messages with Parser and passes
+// the decoded PDU to OnPayload, so that the upper layer gets its PDU in one step.
+type PayloadHandler struct {
+	Parser PayloadParser
+
+	// Callbacks
+	OnPayload func(m Message, pdu any)
+	OnError   func(m Message, err error)
+}
+
+// NewPayloadHandler creates a new PayloadHandler.
+func NewPayloadHandler(p PayloadParser, fn func(m Message, pdu any)) *PayloadHandler {
+	return &PayloadHandler{
+		Parser:    p,
+		OnPayload: fn,
+	}
+}
+
+// HandleMessage decodes the payload in m and calls OnPayload with it.
+// The error is passed to OnError if set, or just logged otherwise. Without Parser,
+// every message fails with ErrNoPayloadParser.
+func (h *PayloadHandler) HandleMessage(m Message) {
+	pdu, err := DecodePayload(m, h.Parser)
+	if err != nil {
+		if h.OnError != nil {
+			h.OnError(m, err)
+			return
+		}
+		logf("Failed to handle %s: %s", m.MessageTypeName(), err)
+		return
+	}
+
+	if h.OnPayload != nil {
+		h.OnPayload(m, pdu)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+)
+
+type testPDU struct {
+	Tag   uint8
+	Value []byte
+}
+
+func parseTestPDU(b []byte) (*testPDU, error) {
+	if len(b) < 2 || int(b[1]) != len(b)-2 {
+		return nil, errors.New("malformed PDU")
+	}
+	return &testPDU{Tag: b[0], Value: b[2:]}, nil
+}
+
+func TestDecodePayload(t *testing.T) {
+	parser := sccp.ParserOf(parseTestPDU)
+
+	t.Run("XUDT", func(t *testing.T) {
+		b, err := newTestXUDT([]byte{0x62, 0x02, 0xca, 0xfe}).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := sccp.ParseXUDT(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pdu, err := x.DecodePayload(parser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := pdu.(*testPDU)
+		if !ok {
+			t.Fatalf("got %T, want *testPDU", pdu)
+		}
+		if got.Tag != 0x62 || string(got.Value) != "\xca\xfe" {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		x := newTestXUDT([]byte{0x62, 0x05, 0xca, 0xfe})
+		if _, err := x.DecodePayload(parser); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestPayloadHandler(t *testing.T) {
+	var pdus []*testPDU
+	var errs []error
+	h := sccp.NewPayloadHandler(sccp.ParserOf(parseTestPDU), func(m sccp.Message, pdu any) {
+		pdus = append(pdus, pdu.(*testPDU))
+	})
+	h.OnError = func(m sccp.Message, err error) {
+		errs = append(errs, err)
+	}
+
+	h.HandleMessage(newTestXUDT([]byte{0x62, 0x01, 0x00}))
+	h.HandleMessage(sccp.NewUDTS(
+		params.ReturnCauseSubsystemFailure,
+		params.NewCalledPartyAddress(0x42, 0, 6, nil),
+		params.NewCallingPartyAddress(0x42, 0, 7, nil),
+		[]byte{0x64, 0x00},
+	))
+	h.HandleMessage(newTestXUDT(nil))
+
+	if got, want := len(pdus), 2; got != want {
+		t.Errorf("got %d PDUs, want %d", got, want)
+	}
+	if got, want := len(errs), 1; got != want {
+		t.Errorf("got %d errors, want %d", got, want)
+	}
+}
+
+func TestPayloadHandlerNoParser(t *testing.T) {
+	for _, p := range []sccp.PayloadParser{
+		nil,
+		sccp.PayloadParserFunc(nil),
+		sccp.ParserOf[*testPDU](nil),
+	} {
+		var errs []error
+		h := sccp.NewPayloadHandler(p, func(m sccp.Message, pdu any) {
+			t.Errorf("got %v, want no PDU", pdu)
+		})
+		h.OnError = func(m sccp.Message, err error) {
+			errs = append(errs, err)
+		}
+
+		h.HandleMessage(newTestXUDT([]byte{0x62, 0x00}))
+		if len(errs) != 1 || !errors.Is(errs[0], sccp.ErrNoPayloadParser) {
+			t.Errorf("got %v, want %v", errs, sccp.ErrNoPayloadParser)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"sync"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// PointCodeManager manages the accessibility of the remote signaling points
+// indicated by MTP-PAUSE and MTP-RESUME.
+//
+// Point codes are accessible unless paused.
+type PointCodeManager struct {
+	paused map[params.PointCode]struct{}
+	mutex  sync.RWMutex
+
+	// Callbacks
+	OnStateChange func(pc params.PointCode, accessible bool)
+}
+
+// NewPointCodeManager creates a new PointCodeManager.
+func NewPointCodeManager() *PointCodeManager {
+	return &PointCodeManager{
+		paused: make(map[params.PointCode]struct{}),
+	}
+}
+
+// HandlePause handles MTP-PAUSE indication, marking pc inaccessible.
+func (pm *PointCodeManager) HandlePause(pc params.PointCode) {
+	pm.mutex.Lock()
+	_, ok := pm.paused[pc]
+	pm.paused[pc] = struct{}{}
+	pm.mutex.Unlock()
+
+	if ok {
+		return
+	}
+
+	if pm.OnStateChange != nil {
+		pm.OnStateChange(pc, false)
+	}
+	logf("Signaling point inaccessible: PC=%s", pc)
+}
+
+// HandleResume handles MTP-RESUME indication, marking pc accessible.
+func (pm *PointCodeManager) HandleResume(pc params.PointCode) {
+	pm.mutex.Lock()
+	_, ok := pm.paused[pc]
+	delete(pm.paused, pc)
+	pm.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if pm.OnStateChange != nil {
+		pm.OnStateChange(pc, true)
+	}
+	logf("Signaling point accessible: PC=%s", pc)
+}
+
+// IsAccessible reports whether pc is accessible.
+func (pm *PointCodeManager) IsAccessible(pc params.PointCode) bool {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	_, ok := pm.paused[pc]
+	return !ok
+}
@@ -0,0 +1,99 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// PolicyAction is an action decided by a Policy.
+type PolicyAction uint8
+
+// PolicyAction values.
+const (
+	// PolicyActionAllow lets the message go through to the next Policy.
+	PolicyActionAllow PolicyAction = iota
+	// PolicyActionReject discards the message, returning a UDTS or XUDTS to the originator
+	// if the return option is set in the message.
+	PolicyActionReject
+	// PolicyActionRewrite replaces the Called and/or Calling Party Address in the
+	// message and lets it go through to the next Policy.
+	PolicyActionRewrite
+)
+
+// String returns the PolicyAction in string.
+func (a PolicyAction) String() string {
+	switch a {
+	case PolicyActionAllow:
+		return "allow"
+	case PolicyActionReject:
+		return "reject"
+	case PolicyActionRewrite:
+		return "rewrite"
+	default:
+		return fmt.Sprintf("PolicyAction(%d)", a)
+	}
+}
+
+// PolicyVerdict is a decision made by a Policy for a message.
+type PolicyVerdict struct {
+	Action PolicyAction
+
+	// ReturnCause is the cause to be set in the UDTS or XUDTS on PolicyActionReject.
+	ReturnCause params.ReturnCauseValue
+
+	// CalledPartyAddress and CallingPartyAddress are the addresses to replace the
+	// ones in the message with on PolicyActionRewrite. nil keeps the original one.
+	CalledPartyAddress  *params.PartyAddress
+	CallingPartyAddress *params.PartyAddress
+}
+
+// PolicyAllow returns a PolicyVerdict that allows the message.
+func PolicyAllow() PolicyVerdict {
+	return PolicyVerdict{Action: PolicyActionAllow}
+}
+
+// PolicyReject returns a PolicyVerdict that rejects the message with the given cause.
+func PolicyReject(cause params.ReturnCauseValue) PolicyVerdict {
+	return PolicyVerdict{Action: PolicyActionReject, ReturnCause: cause}
+}
+
+// PolicyRewrite returns a PolicyVerdict that rewrites the addresses in the message.
+// Give nil to keep the original address.
+func PolicyRewrite(cdpa, cgpa *params.PartyAddress) PolicyVerdict {
+	return PolicyVerdict{
+		Action:              PolicyActionRewrite,
+		CalledPartyAddress:  cdpa,
+		CallingPartyAddress: cgpa,
+	}
+}
+
+// String returns the PolicyVerdict in human readable format.
+func (v PolicyVerdict) String() string {
+	switch v.Action {
+	case PolicyActionReject:
+		return fmt.Sprintf("%s (%s)", v.Action, v.ReturnCause)
+	default:
+		return v.Action.String()
+	}
+}
+
+// Policy is an interface to screen the messages before they are delivered.
+//
+// Screen is called with the message and its parsed Called/Calling Party Address.
+// The addresses must not be modified in place; use PolicyRewrite instead.
+type Policy interface {
+	Screen(m Message, cdpa, cgpa *params.PartyAddress) PolicyVerdict
+}
+
+// PolicyFunc is an adapter to use an ordinary function as a Policy.
+type PolicyFunc func(m Message, cdpa, cgpa *params.PartyAddress) PolicyVerdict
+
+// Screen calls f(m, cdpa, cgpa).
+func (f PolicyFunc) Screen(m Message, cdpa, cgpa *params.PartyAddress) PolicyVerdict {
+	return f(m, cdpa, cgpa)
+}
@@ -0,0 +1,128 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+var (
+	udtPool  = sync.Pool{New: func() any { return &UDT{} }}
+	xudtPool = sync.Pool{New: func() any { return &XUDT{} }}
+	scmgPool = sync.Pool{New: func() any { return &SCMG{} }}
+)
+
+// AcquireUDT returns an empty UDT from the pool.
+//
+// Give it back with ReleaseUDT when it is no longer used, to reduce the allocations
+// in the hot path. The UDT must not be used after it is released.
+func AcquireUDT() *UDT {
+	return udtPool.Get().(*UDT)
+}
+
+// ReleaseUDT resets u and puts it back to the pool.
+func ReleaseUDT(u *UDT) {
+	if u == nil {
+		return
+	}
+	u.Reset()
+	udtPool.Put(u)
+}
+
+// AcquireXUDT returns an empty XUDT from the pool.
+//
+// Give it back with ReleaseXUDT when it is no longer used, to reduce the allocations
+// in the hot path. The XUDT must not be used after it is released.
+func AcquireXUDT() *XUDT {
+	return xudtPool.Get().(*XUDT)
+}
+
+// ReleaseXUDT resets x and puts it back to the pool.
+func ReleaseXUDT(x *XUDT) {
+	if x == nil {
+		return
+	}
+	x.Reset()
+	xudtPool.Put(x)
+}
+
+// AcquireSCMG returns an empty SCMG from the pool.
+//
+// Give it back with ReleaseSCMG when it is no longer used, to reduce the allocations
+// in the hot path. The SCMG must not be used after it is released.
+func AcquireSCMG() *SCMG {
+	return scmgPool.Get().(*SCMG)
+}
+
+// ReleaseSCMG resets s and puts it back to the pool.
+func ReleaseSCMG(s *SCMG) {
+	if s == nil {
+		return
+	}
+	s.Reset()
+	scmgPool.Put(s)
+}
+
+// ParseMessageInto decodes b into m, which is reset before decoding.
+//
+// It fails if the type of b is not the type of m. As with ParseMessage, the Data
+// in m refers to b without copying unless params.WithOwnedData is given.
+func ParseMessageInto(b []byte, m Message, opts ...params.DataOption) error {
+	if len(b) < 1 {
+		return fmt.Errorf("invalid SCCP message %v: %w", b, io.ErrUnexpectedEOF)
+	}
+	if got, want := MsgType(b[0]), m.MessageType(); got != want {
+		return fmt.Errorf("cannot decode %s into %s", got, want)
+	}
+
+	if r, ok := m.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+	if err := m.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	applyDataOptions(m, opts)
+	return nil
+}
+
+// ParseMessagePooled is the same as ParseMessage, but takes the UDT and XUDT from
+// the pool. Give the message back with ReleaseMessage when it is no longer used.
+func ParseMessagePooled(b []byte, opts ...params.DataOption) (Message, error) {
+	if len(b) < 1 {
+		return nil, fmt.Errorf("invalid SCCP message %v: %w", b, io.ErrUnexpectedEOF)
+	}
+
+	var m Message
+	switch MsgType(b[0]) {
+	case MsgTypeUDT:
+		m = AcquireUDT()
+	case MsgTypeXUDT:
+		m = AcquireXUDT()
+	default:
+		return ParseMessage(b, opts...)
+	}
+
+	if err := m.UnmarshalBinary(b); err != nil {
+		ReleaseMessage(m)
+		return nil, err
+	}
+	applyDataOptions(m, opts)
+	return m, nil
+}
+
+// ReleaseMessage puts m back to the pool if m is of the pooled types, i.e.,
+// UDT or XUDT. It does nothing for the other types.
+func ReleaseMessage(m Message) {
+	switch m := m.(type) {
+	case *UDT:
+		ReleaseUDT(m)
+	case *XUDT:
+		ReleaseXUDT(m)
+	}
+}
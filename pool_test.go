@@ -0,0 +1,136 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func TestReset(t *testing.T) {
+	cdpa := params.NewCalledPartyAddress(0x42, 0, 6, nil)
+	cgpa := params.NewCallingPartyAddress(0x42, 0, 7, nil)
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	cases := []struct {
+		msg   interface{ Reset() }
+		empty any
+	}{
+		{sccp.NewUDT(1, true, cdpa, cgpa, data), &sccp.UDT{}},
+		{sccp.NewXUDT(1, true, 15, cdpa, cgpa, data, params.NewImportance(1)), &sccp.XUDT{}},
+		{sccp.NewUDTS(params.ReturnCauseUnqualified, cdpa, cgpa, data), &sccp.UDTS{}},
+		{sccp.NewXUDTS(params.ReturnCauseUnqualified, 15, cdpa, cgpa, data), &sccp.XUDTS{}},
+		{sccp.NewSCMG(sccp.SCMGTypeSSC, 6, 0x1234, 0, 3), &sccp.SCMG{}},
+	}
+
+	for _, c := range cases {
+		c.msg.Reset()
+		if !verify.Values(t, "", c.msg, c.empty) {
+			t.Errorf("%T is not cleared", c.msg)
+		}
+	}
+}
+
+func TestParseMessageInto(t *testing.T) {
+	segmented := newTestXUDT([]byte{0xde, 0xad})
+	segmented.Segmentation = params.NewSegmentation(true, 1, 2, 0x123456)
+	segmented.SetPointers()
+	withOpts, err := segmented.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	noOpts, err := newTestXUDT([]byte{0xbe, 0xef}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := sccp.AcquireXUDT()
+	defer sccp.ReleaseXUDT(x)
+
+	for _, b := range [][]byte{withOpts, noOpts} {
+		if err := sccp.ParseMessageInto(b, x); err != nil {
+			t.Fatal(err)
+		}
+
+		want, err := sccp.ParseXUDT(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verify.Values(t, "", x, want) {
+			t.Fail()
+		}
+	}
+
+	if err := sccp.ParseMessageInto(noOpts, sccp.AcquireUDT()); err == nil {
+		t.Error("got no error decoding XUDT into UDT")
+	}
+}
+
+func TestParseMessagePooled(t *testing.T) {
+	var msgs [][]byte
+	for _, c := range testcases {
+		if _, ok := c.structured.(sccp.Message); ok {
+			msgs = append(msgs, c.serialized)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b := msgs[j%len(msgs)]
+				m, err := sccp.ParseMessagePooled(b)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				got, err := m.MarshalBinary()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if string(got) != string(b) {
+					t.Errorf("got %x, want %x", got, b)
+				}
+				sccp.ReleaseMessage(m)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkParseMessage(b *testing.B) {
+	buf, err := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef}).MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := sccp.ParseMessage(buf); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m, err := sccp.ParseMessagePooled(buf)
+			if err != nil {
+				b.Fatal(err)
+			}
+			sccp.ReleaseMessage(m)
+		}
+	})
+}
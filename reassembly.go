@@ -0,0 +1,321 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// DefaultReassemblyTimeout is the default value of the reassembly timer T(reass).
+// Q.714 recommends 10 to 20 seconds.
+const DefaultReassemblyTimeout = 10 * time.Second
+
+// DefaultMaxReassemblyContexts is the default number of the segmented messages
+// reassembled at the same time.
+const DefaultMaxReassemblyContexts = 1024
+
+// NoticeReason is the reason why a segmented message could not be reassembled.
+type NoticeReason uint8
+
+// NoticeReason values.
+const (
+	_                             NoticeReason = iota
+	NoticeReasonTimeout                        // T(reass) expired
+	NoticeReasonOutOfSequence                  // segment not following the previous one
+	NoticeReasonNoFirstSegment                 // segment without the first segment
+	NoticeReasonTooManyContexts                // MaxContexts reached
+	NoticeReasonInconsistentClass              // protocol class differs from the first segment
+)
+
+// String returns the NoticeReason in human readable format.
+func (r NoticeReason) String() string {
+	switch r {
+	case NoticeReasonTimeout:
+		return "reassembly timeout"
+	case NoticeReasonOutOfSequence:
+		return "segment out of sequence"
+	case NoticeReasonNoFirstSegment:
+		return "no first segment"
+	case NoticeReasonTooManyContexts:
+		return "too many reassembly contexts"
+	case NoticeReasonInconsistentClass:
+		return "inconsistent protocol class"
+	default:
+		return fmt.Sprintf("unknown reason(%d)", uint8(r))
+	}
+}
+
+// Notice is an N-NOTICE-like indication of a segmented message that failed to be
+// reassembled.
+type Notice struct {
+	Reason              NoticeReason
+	LocalReference      uint32
+	CalledPartyAddress  *params.PartyAddress
+	CallingPartyAddress *params.PartyAddress
+
+	// Data is the user data of the segments received so far, in order.
+	Data     []byte
+	Segments int
+
+	// Return is the XUDTS to be sent back to the originator. It is nil unless
+	// ReturnOnFailure is set in Reassembler and the return option is set in the
+	// segment that started the reassembly.
+	Return Message
+}
+
+// String returns the Notice in human readable format.
+func (n *Notice) String() string {
+	return fmt.Sprintf("{Reason: %s, SLR: %#06x, CalledPartyAddress: %v, CallingPartyAddress: %v, Segments: %d, Data: %x}",
+		n.Reason, n.LocalReference, n.CalledPartyAddress, n.CallingPartyAddress, n.Segments, n.Data,
+	)
+}
+
+// reassemblyKey identifies a segmented message by the Segmentation Local Reference
+// and the Calling Party Address, as described in Q.714 4.1.1.2.3.
+type reassemblyKey struct {
+	localReference uint32
+	calling        string
+}
+
+type reassemblyContext struct {
+	key       reassemblyKey
+	cdpa      *params.PartyAddress
+	cgpa      *params.PartyAddress
+	pcls      *params.ProtocolClass
+	first     []byte
+	data      []byte
+	segments  int
+	remaining uint8
+	timer     *time.Timer
+}
+
+// Reassembler reassembles the segmented XUDT.
+//
+// A reassembly is started by the first segment, and the timer T(reass) (Timeout)
+// is started for it. The user data of the following segments are appended in
+// order, and the whole user data is returned with the last segment. When T(reass)
+// expires, or the segments are not consistent, the reassembly is given up and
+// OnNotice is called with the user data received so far and the reason.
+//
+// The number of the reassembly in progress is bounded by MaxContexts to limit the
+// memory usage; the first segments exceeding it are refused with a Notice.
+//
+// OnNotice, and the sending of Notice.Return by the Stack, are called in the
+// goroutine of Reassemble for the inconsistent segments, but in the goroutine of
+// the timer on T(reass) expiry. They must be safe to call concurrently.
+//
+// The zero value uses DefaultReassemblyTimeout and DefaultMaxReassemblyContexts,
+// and does not return XUDTS. NewReassembler returns one with ReturnOnFailure set.
+//
+// Reassembler is safe for concurrent use.
+type Reassembler struct {
+	mu       sync.Mutex
+	contexts map[reassemblyKey]*reassemblyContext
+
+	// send is set by the Stack using the Reassembler to send the Notice.Return.
+	send func(m Message) error
+
+	// Configuration
+	Timeout         time.Duration // T(reass); DefaultReassemblyTimeout if not positive
+	MaxContexts     int           // DefaultMaxReassemblyContexts if not positive
+	ReturnOnFailure bool
+
+	// Callbacks
+	OnNotice func(n *Notice)
+}
+
+// NewReassembler creates a new Reassembler.
+func NewReassembler() *Reassembler {
+	return &Reassembler{
+		contexts:        make(map[reassemblyKey]*reassemblyContext),
+		Timeout:         DefaultReassemblyTimeout,
+		MaxContexts:     DefaultMaxReassemblyContexts,
+		ReturnOnFailure: true,
+	}
+}
+
+// Reassemble adds the segment x to the reassembly it belongs to.
+//
+// It returns the whole user data and true when x completes the reassembly. The
+// user data of x is returned as is if x is not segmented. Otherwise it returns
+// false; if x cannot be reassembled, OnNotice is called before it returns.
+//
+// The user data and the Party Addresses of x are copied, so x may refer to a
+// buffer reused after Reassemble returns.
+func (r *Reassembler) Reassemble(x *XUDT) ([]byte, bool) {
+	seg := x.Segmentation
+	if seg == nil {
+		return x.Data.Value(), true
+	}
+
+	key := reassemblyKey{localReference: seg.LocalReference}
+	if x.CallingPartyAddress != nil {
+		key.calling = x.CallingPartyAddress.Address()
+	}
+
+	var notices []*Notice
+	defer func() {
+		for _, n := range notices {
+			r.notify(n)
+		}
+	}()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.contexts == nil {
+		r.contexts = make(map[reassemblyKey]*reassemblyContext)
+	}
+
+	c, ok := r.contexts[key]
+	if seg.FirstSegment {
+		if ok {
+			// the previous one is abandoned by the originator.
+			r.remove(c)
+			notices = append(notices, r.notice(c, NoticeReasonOutOfSequence))
+		}
+
+		if seg.RemainingSegments == 0 {
+			return x.Data.Value(), true
+		}
+
+		c = newReassemblyContext(key, x)
+		limit := r.MaxContexts
+		if limit <= 0 {
+			limit = DefaultMaxReassemblyContexts
+		}
+		if len(r.contexts) >= limit {
+			notices = append(notices, r.notice(c, NoticeReasonTooManyContexts))
+			return nil, false
+		}
+
+		r.contexts[key] = c
+		timeout := r.Timeout
+		if timeout <= 0 {
+			timeout = DefaultReassemblyTimeout
+		}
+		c.timer = time.AfterFunc(timeout, func() { r.expire(c) })
+		return nil, false
+	}
+
+	if !ok {
+		notices = append(notices, r.notice(newReassemblyContext(key, x), NoticeReasonNoFirstSegment))
+		return nil, false
+	}
+
+	if x.ProtocolClass != nil && c.pcls != nil && x.ProtocolClass.Class() != c.pcls.Class() {
+		r.remove(c)
+		notices = append(notices, r.notice(c, NoticeReasonInconsistentClass))
+		return nil, false
+	}
+
+	if seg.RemainingSegments+1 != c.remaining {
+		r.remove(c)
+		notices = append(notices, r.notice(c, NoticeReasonOutOfSequence))
+		return nil, false
+	}
+
+	c.data = append(c.data, x.Data.Value()...)
+	c.segments++
+	c.remaining = seg.RemainingSegments
+	if c.remaining > 0 {
+		return nil, false
+	}
+
+	r.remove(c)
+	return c.data, true
+}
+
+// Len returns the number of the reassembly in progress.
+func (r *Reassembler) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.contexts)
+}
+
+// expire gives up the reassembly on T(reass) expiry.
+func (r *Reassembler) expire(c *reassemblyContext) {
+	r.mu.Lock()
+	if r.contexts[c.key] != c {
+		// completed or given up in the meantime.
+		r.mu.Unlock()
+		return
+	}
+	delete(r.contexts, c.key)
+	n := r.notice(c, NoticeReasonTimeout)
+	r.mu.Unlock()
+
+	r.notify(n)
+}
+
+// remove removes c and stops its timer. It should be called with the mutex held.
+func (r *Reassembler) remove(c *reassemblyContext) {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	delete(r.contexts, c.key)
+}
+
+// notice creates a Notice of c. It should be called with the mutex held.
+func (r *Reassembler) notice(c *reassemblyContext, reason NoticeReason) *Notice {
+	n := &Notice{
+		Reason:              reason,
+		LocalReference:      c.key.localReference,
+		CalledPartyAddress:  c.cdpa,
+		CallingPartyAddress: c.cgpa,
+		Data:                c.data,
+		Segments:            c.segments,
+	}
+
+	if r.ReturnOnFailure && c.pcls != nil && c.pcls.ReturnOnError() {
+		n.Return = NewXUDTS(params.ReturnCauseSegmentationFailure, DefaultHopCounter, c.cgpa, c.cdpa, c.first)
+	}
+	return n
+}
+
+func (r *Reassembler) notify(n *Notice) {
+	logf("Reassembly failed: %s", n)
+
+	r.mu.Lock()
+	send := r.send
+	r.mu.Unlock()
+
+	if n.Return != nil && send != nil {
+		if err := send(n.Return); err != nil {
+			logf("Failed to return %s: %s", n.Return.MessageTypeName(), err)
+		}
+	}
+
+	if r.OnNotice != nil {
+		r.OnNotice(n)
+	}
+}
+
+// setSender sets the function to send Notice.Return with.
+func (r *Reassembler) setSender(send func(m Message) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.send = send
+}
+
+func newReassemblyContext(key reassemblyKey, x *XUDT) *reassemblyContext {
+	data := append([]byte(nil), x.Data.Value()...)
+	return &reassemblyContext{
+		key:       key,
+		cdpa:      cloneAddress(x.CalledPartyAddress),
+		cgpa:      cloneAddress(x.CallingPartyAddress),
+		pcls:      x.ProtocolClass,
+		first:     data,
+		data:      data,
+		segments:  1,
+		remaining: x.Segmentation.RemainingSegments,
+	}
+}
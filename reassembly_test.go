@@ -0,0 +1,239 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func newTestSegment(first bool, rem uint8, lrn uint32, pcls int, data ...byte) *sccp.XUDT {
+	return sccp.NewXUDT(
+		pcls, true, sccp.DefaultHopCounter,
+		params.NewCalledPartyAddress(0x42, 0, 6, nil),
+		params.NewCallingPartyAddress(0x42, 0, 7, nil),
+		data,
+		params.NewSegmentation(first, uint8(pcls), rem, lrn),
+	)
+}
+
+func TestReassemble(t *testing.T) {
+	cases := []struct {
+		description string
+		segments    []*sccp.XUDT
+		want        []byte
+		notices     []sccp.NoticeReason
+		noticeData  []byte
+	}{
+		{
+			"In sequence",
+			[]*sccp.XUDT{
+				newTestSegment(true, 2, 1, 1, 0x01, 0x02),
+				newTestSegment(false, 1, 1, 1, 0x03),
+				newTestSegment(false, 0, 1, 1, 0x04),
+			},
+			[]byte{0x01, 0x02, 0x03, 0x04},
+			nil, nil,
+		},
+		{
+			"Single segment",
+			[]*sccp.XUDT{newTestSegment(true, 0, 1, 1, 0x01)},
+			[]byte{0x01},
+			nil, nil,
+		},
+		{
+			"Interleaved",
+			[]*sccp.XUDT{
+				newTestSegment(true, 1, 1, 1, 0x01),
+				newTestSegment(true, 1, 2, 1, 0x11),
+				newTestSegment(false, 0, 2, 1, 0x12),
+				newTestSegment(false, 0, 1, 1, 0x02),
+			},
+			[]byte{0x01, 0x02},
+			nil, nil,
+		},
+		{
+			"Missing segment",
+			[]*sccp.XUDT{
+				newTestSegment(true, 2, 1, 1, 0x01),
+				newTestSegment(false, 0, 1, 1, 0x03),
+			},
+			nil,
+			[]sccp.NoticeReason{sccp.NoticeReasonOutOfSequence},
+			[]byte{0x01},
+		},
+		{
+			"No first segment",
+			[]*sccp.XUDT{newTestSegment(false, 0, 1, 1, 0x02)},
+			nil,
+			[]sccp.NoticeReason{sccp.NoticeReasonNoFirstSegment},
+			[]byte{0x02},
+		},
+		{
+			"First segment again",
+			[]*sccp.XUDT{
+				newTestSegment(true, 2, 1, 1, 0x01),
+				newTestSegment(false, 1, 1, 1, 0x02),
+				newTestSegment(true, 1, 1, 1, 0x11),
+				newTestSegment(false, 0, 1, 1, 0x12),
+			},
+			[]byte{0x11, 0x12},
+			[]sccp.NoticeReason{sccp.NoticeReasonOutOfSequence},
+			[]byte{0x01, 0x02},
+		},
+		{
+			"Inconsistent class",
+			[]*sccp.XUDT{
+				newTestSegment(true, 1, 1, 1, 0x01),
+				newTestSegment(false, 0, 1, 0, 0x02),
+			},
+			nil,
+			[]sccp.NoticeReason{sccp.NoticeReasonInconsistentClass},
+			[]byte{0x01},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var notices []*sccp.Notice
+			r := sccp.NewReassembler()
+			r.OnNotice = func(n *sccp.Notice) {
+				notices = append(notices, n)
+			}
+
+			var got []byte
+			for _, x := range c.segments {
+				if data, ok := r.Reassemble(x); ok {
+					got = data
+				}
+			}
+			if !verify.Values(t, "", got, c.want) {
+				t.Fail()
+			}
+
+			var reasons []sccp.NoticeReason
+			for _, n := range notices {
+				reasons = append(reasons, n.Reason)
+			}
+			if !verify.Values(t, "", reasons, c.notices) {
+				t.Fail()
+			}
+			if len(notices) > 0 && !verify.Values(t, "", notices[0].Data, c.noticeData) {
+				t.Fail()
+			}
+			if got := r.Len(); got != 0 {
+				t.Errorf("got %d reassembly in progress, want 0", got)
+			}
+		})
+	}
+}
+
+func TestReassembleTimeout(t *testing.T) {
+	notices := make(chan *sccp.Notice, 1)
+	r := sccp.NewReassembler()
+	r.Timeout = 10 * time.Millisecond
+	r.OnNotice = func(n *sccp.Notice) {
+		notices <- n
+	}
+
+	for _, x := range []*sccp.XUDT{
+		newTestSegment(true, 2, 0x123456, 1, 0x01),
+		newTestSegment(false, 1, 0x123456, 1, 0x02),
+	} {
+		if _, ok := r.Reassemble(x); ok {
+			t.Fatal("got reassembled before the last segment")
+		}
+	}
+
+	var n *sccp.Notice
+	select {
+	case n = <-notices:
+	case <-time.After(time.Second):
+		t.Fatal("T(reass) not expired")
+	}
+
+	if got, want := n.Reason, sccp.NoticeReasonTimeout; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := n.LocalReference, uint32(0x123456); got != want {
+		t.Errorf("got SLR %#06x, want %#06x", got, want)
+	}
+	if got, want := n.Segments, 2; got != want {
+		t.Errorf("got %d segments, want %d", got, want)
+	}
+	if !verify.Values(t, "", n.Data, []byte{0x01, 0x02}) {
+		t.Fail()
+	}
+
+	xudts, ok := n.Return.(*sccp.XUDTS)
+	if !ok {
+		t.Fatalf("got %T, want *sccp.XUDTS", n.Return)
+	}
+	if got, want := xudts.Cause(), params.ReturnCauseSegmentationFailure; got != want {
+		t.Errorf("got cause %s, want %s", got, want)
+	}
+	if got, want := xudts.CalledPartyAddress.SubsystemNumber, uint8(7); got != want {
+		t.Errorf("got XUDTS to SSN %d, want %d", got, want)
+	}
+	if !verify.Values(t, "", xudts.Data.Value(), []byte{0x01}) {
+		t.Fail()
+	}
+
+	if _, ok := r.Reassemble(newTestSegment(false, 0, 0x123456, 1, 0x03)); ok {
+		t.Error("got reassembled after T(reass) expiry")
+	}
+}
+
+func TestReassembleMaxContexts(t *testing.T) {
+	var notices []*sccp.Notice
+	r := sccp.NewReassembler()
+	r.MaxContexts = 2
+	r.ReturnOnFailure = false
+	r.OnNotice = func(n *sccp.Notice) {
+		notices = append(notices, n)
+	}
+
+	for lrn := uint32(1); lrn <= 3; lrn++ {
+		r.Reassemble(newTestSegment(true, 1, lrn, 1, byte(lrn)))
+	}
+	if got, want := r.Len(), 2; got != want {
+		t.Errorf("got %d reassembly in progress, want %d", got, want)
+	}
+	if len(notices) != 1 {
+		t.Fatalf("got %d notices, want 1", len(notices))
+	}
+	if got, want := notices[0].Reason, sccp.NoticeReasonTooManyContexts; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := notices[0].LocalReference, uint32(3); got != want {
+		t.Errorf("got SLR %d, want %d", got, want)
+	}
+	if notices[0].Return != nil {
+		t.Errorf("got %v, want no return with ReturnOnFailure unset", notices[0].Return)
+	}
+
+	if data, ok := r.Reassemble(newTestSegment(false, 0, 1, 1, 0x11)); !ok || !verify.Values(t, "", data, []byte{0x01, 0x11}) {
+		t.Errorf("got %x, %v, want reassembled", data, ok)
+	}
+}
+
+func TestReassemblerZeroValue(t *testing.T) {
+	r := &sccp.Reassembler{}
+	if _, ok := r.Reassemble(newTestSegment(true, 1, 1, 1, 0x01)); ok {
+		t.Fatal("got reassembled with the first segment")
+	}
+
+	// T(reass) must not expire immediately.
+	time.Sleep(10 * time.Millisecond)
+
+	data, ok := r.Reassemble(newTestSegment(false, 0, 1, 1, 0x02))
+	if !ok || !verify.Values(t, "", data, []byte{0x01, 0x02}) {
+		t.Errorf("got %x, %v, want reassembled", data, ok)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+var strict = flag.Bool("strict", true, "require the canonical golden vectors to be re-encoded byte-for-byte; with -strict=false, every vector is only required to round-trip semantically")
+
+func parseMessage(b []byte) (serializable, error) {
+	return sccp.ParseMessage(b)
+}
+
+func parseSCMG(b []byte) (serializable, error) {
+	return sccp.ParseSCMG(b)
+}
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// vectors are the messages encoded by hand from the formats in Q.713.
+//
+// canonical is false for the vectors that are valid but not in the form this
+// package encodes, e.g., the optional parameters in another order. They are
+// required to round-trip semantically, not byte-for-byte, even with -strict.
+var vectors = []struct {
+	description string
+	serialized  string
+	parseFunc   func([]byte) (serializable, error)
+	canonical   bool
+}{
+	{
+		description: "UDT/Route on GT, E.164",
+		serialized: `
+			09 81 03 0e 18
+			0b 12 06 00 11 04 13 16 32 54 76 08
+			0a 12 08 00 12 04 13 16 00 00 00
+			08 62 06 48 04 01 02 03 04`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "UDT/Route on SSN with PC",
+		serialized: `
+			09 00 03 07 0b
+			04 43 34 12 08
+			04 43 21 00 08
+			03 aa bb cc`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "UDT/GT with TT only",
+		serialized: `
+			09 01 03 08 0a
+			05 0a 07 0a 21 43
+			02 42 07
+			01 00`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "UDT/SCMG SST",
+		serialized: `
+			09 00 03 05 07
+			02 42 01
+			02 42 01
+			05 03 06 34 12 00`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "XUDT/First segment with Importance",
+		serialized: `
+			11 81 0f 04 06 08 0c
+			02 42 06
+			02 42 07
+			04 de ad be ef
+			10 04 c2 00 00 01
+			12 01 03
+			00`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "XUDT/Last segment",
+		serialized: `
+			11 01 0e 04 06 08 0a
+			02 42 06
+			02 42 07
+			02 ca fe
+			10 04 40 00 00 01
+			00`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "XUDT/Importance before Segmentation",
+		serialized: `
+			11 81 0f 04 06 08 0c
+			02 42 06
+			02 42 07
+			04 de ad be ef
+			12 01 03
+			10 04 c2 00 00 01
+			00`,
+		parseFunc: parseMessage,
+		canonical: false,
+	},
+	{
+		description: "UDTS/No translation for this specific address",
+		serialized: `
+			0a 01 03 05 07
+			02 42 07
+			02 42 06
+			04 de ad be ef`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "XUDTS/Hop counter violation",
+		serialized: `
+			12 0c 0f 04 06 08 00
+			02 42 07
+			02 42 06
+			02 ca fe`,
+		parseFunc: parseMessage,
+		canonical: true,
+	},
+	{
+		description: "SCMG SSP",
+		serialized:  `02 06 34 12 00`,
+		parseFunc:   parseSCMG,
+		canonical:   true,
+	},
+	{
+		description: "SCMG SSC",
+		serialized:  `06 08 34 12 00 03`,
+		parseFunc:   parseSCMG,
+		canonical:   true,
+	},
+}
+
+func TestGoldenVectors(t *testing.T) {
+	for _, c := range vectors {
+		t.Run(c.description, func(t *testing.T) {
+			serialized := unhex(c.serialized)
+			decoded, err := c.parseFunc(serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := decoded.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.canonical && *strict {
+				if got, want := b, serialized; !verify.Values(t, "", got, want) {
+					t.Fail()
+				}
+			}
+
+			redecoded, err := c.parseFunc(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := redecoded, decoded; !verify.Values(t, "", got, want) {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func randomPartyAddress(rnd *rand.Rand, newAddr func(uint8, params.PointCode, uint8, *params.GlobalTitle) *params.PartyAddress) *params.PartyAddress {
+	hasPC := rnd.Intn(2) == 0
+	gti := params.GlobalTitleIndicator(rnd.Intn(5))
+	routeOnSSN := gti == params.GTINoGT || rnd.Intn(2) == 0
+
+	var gt *params.GlobalTitle
+	if gti != params.GTINoGT {
+		digits := make([]byte, randomLength(rnd, 64))
+		rnd.Read(digits)
+		gt = params.NewGlobalTitle(
+			gti,
+			params.TranslationType(rnd.Intn(0x100)),
+			params.NumberingPlan(rnd.Intn(0x10)),
+			params.EncodingScheme(1+rnd.Intn(2)),
+			params.NatureOfAddressIndicator(rnd.Intn(0x80)),
+			digits,
+		)
+	}
+
+	var pc params.PointCode
+	if hasPC {
+		pc = params.PointCode(rnd.Intn(0x4000))
+	}
+
+	return newAddr(
+		params.NewAddressIndicator(hasPC, true, routeOnSSN, gti),
+		pc, uint8(1+rnd.Intn(0xfe)), gt,
+	)
+}
+
+func randomData(rnd *rand.Rand) []byte {
+	b := make([]byte, randomLength(rnd, 0xff))
+	rnd.Read(b)
+	return b
+}
+
+// randomLength returns a length from 1 to limit, biased toward limit to exercise
+// the size limits.
+func randomLength(rnd *rand.Rand, limit int) int {
+	switch rnd.Intn(4) {
+	case 0:
+		return limit
+	case 1:
+		return limit - rnd.Intn(min(4, limit))
+	default:
+		return 1 + rnd.Intn(limit)
+	}
+}
+
+// randomLongMessage returns a segmented XUDT or a UDT with one of the Pointers
+// at or just below its limit of 255.
+func randomLongMessage(rnd *rand.Rand) sccp.Message {
+	cgpa := randomPartyAddress(rnd, params.NewCallingPartyAddress)
+
+	if rnd.Intn(2) == 0 {
+		cdpa := randomPartyAddress(rnd, params.NewCalledPartyAddress)
+		// the Pointer to the optional part: 1 + CdPA + CgPA + 1 + Data
+		data := make([]byte, 253-cdpa.MarshalLen()-cgpa.MarshalLen()-rnd.Intn(3))
+		rnd.Read(data)
+		return sccp.NewXUDT(
+			1, rnd.Intn(2) == 0, uint8(1+rnd.Intn(15)), cdpa, cgpa, data,
+			params.NewSegmentation(rnd.Intn(2) == 0, 1, uint8(rnd.Intn(16)), rnd.Uint32()&0xffffff),
+		)
+	}
+
+	// the Pointer to Data: 1 + CdPA + CgPA
+	ssn := uint8(1 + rnd.Intn(0xfe))
+	digits := 254 - cgpa.MarshalLen() - newLongGTAddress(ssn, 0).MarshalLen() - rnd.Intn(3)
+	return sccp.NewUDT(rnd.Intn(2), rnd.Intn(2) == 0, newLongGTAddress(ssn, digits), cgpa, randomData(rnd))
+}
+
+func randomMessage(rnd *rand.Rand) sccp.Message {
+	cdpa := randomPartyAddress(rnd, params.NewCalledPartyAddress)
+	cgpa := randomPartyAddress(rnd, params.NewCallingPartyAddress)
+	data := randomData(rnd)
+	cause := params.ReturnCauseValue(rnd.Intn(0x0f))
+	hc := uint8(1 + rnd.Intn(15))
+
+	switch rnd.Intn(4) {
+	case 0:
+		return sccp.NewUDT(rnd.Intn(2), rnd.Intn(2) == 0, cdpa, cgpa, data)
+	case 1:
+		var opts []params.Parameter
+		if rnd.Intn(2) == 0 {
+			opts = append(opts, params.NewSegmentation(rnd.Intn(2) == 0, uint8(rnd.Intn(2)), uint8(rnd.Intn(16)), rnd.Uint32()&0xffffff))
+		}
+		if rnd.Intn(2) == 0 {
+			opts = append(opts, params.NewImportance(uint8(rnd.Intn(8))))
+		}
+		return sccp.NewXUDT(rnd.Intn(2), rnd.Intn(2) == 0, hc, cdpa, cgpa, data, opts...)
+	case 2:
+		return sccp.NewUDTS(cause, cdpa, cgpa, data)
+	default:
+		return sccp.NewXUDTS(cause, hc, cdpa, cgpa, data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	n := 1000
+	if testing.Short() {
+		n = 100
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		var m sccp.Message
+		long := rnd.Intn(4) == 0
+		if long {
+			m = randomLongMessage(rnd)
+		} else {
+			m = randomMessage(rnd)
+		}
+
+		b, err := m.MarshalBinary()
+		if err != nil {
+			var lerr *sccp.LengthError
+			if errors.As(err, &lerr) && !long {
+				continue
+			}
+			t.Fatalf("#%d %v: %v", i, m, err)
+		}
+		if got, want := len(b), m.MarshalLen(); got != want {
+			t.Fatalf("#%d %v: got %d octets, want %d", i, m, got, want)
+		}
+
+		decoded, err := sccp.ParseMessage(b)
+		if err != nil {
+			t.Fatalf("#%d %x: %v", i, b, err)
+		}
+		if got, want := decoded, m; !verify.Values(t, "", got, want) {
+			t.Fatalf("#%d %x: decoded message differs", i, b)
+		}
+
+		remarshaled, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := remarshaled, b; !verify.Values(t, "", got, want) {
+			t.Fatalf("#%d: re-encoded message differs", i)
+		}
+	}
+}
+
+func TestRoundTripSCMG(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	types := []sccp.SCMGType{
+		sccp.SCMGTypeSSA, sccp.SCMGTypeSSP, sccp.SCMGTypeSST,
+		sccp.SCMGTypeSOR, sccp.SCMGTypeSOG, sccp.SCMGTypeSSC,
+	}
+
+	for i := 0; i < 100; i++ {
+		typ := types[rnd.Intn(len(types))]
+		var scl uint8
+		if typ == sccp.SCMGTypeSSC {
+			scl = uint8(rnd.Intn(0x10))
+		}
+		s := sccp.NewSCMG(typ, uint8(rnd.Intn(0x100)), params.PointCode(rnd.Intn(0x4000)), uint8(rnd.Intn(4)), scl)
+
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, err := sccp.ParseSCMG(b)
+		if err != nil {
+			t.Fatalf("#%d %x: %v", i, b, err)
+		}
+		if got, want := decoded, s; !verify.Values(t, "", got, want) {
+			t.Fatalf("#%d %x: decoded message differs", i, b)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// DefaultHopCounter is the HopCounter set in the messages generated by this package.
+const DefaultHopCounter uint8 = 15
+
+// ErrNotUnitdata indicates the message given to the Router is not a unitdata message.
+var ErrNotUnitdata = errors.New("sccp: not a unitdata message")
+
+// RouteResult is the result of routing a message with Router.
+type RouteResult struct {
+	// Message is the message to be delivered, which has the addresses rewritten by
+	// the policies if any.
+	Message Message
+
+	// Rejected reports whether the message was rejected, with ReturnCause.
+	Rejected    bool
+	ReturnCause params.ReturnCauseValue
+
+	// Return is the message to be sent back to the originator of the rejected
+	// message. It is nil if the return option is not set in the message.
+	Return Message
+
+	// Destination is the destination resolved by the GTT. It is nil if the message
+	// is routed on SSN or GTT is not set in Router.
+	Destination *Destination
+}
+
+// String returns the RouteResult in human readable format.
+func (r *RouteResult) String() string {
+	if r.Rejected {
+		return fmt.Sprintf("rejected (%s)", r.ReturnCause)
+	}
+	if r.Destination != nil {
+		return fmt.Sprintf("delivered to %s", r.Destination)
+	}
+	return "delivered"
+}
+
+// Router routes the unitdata messages, passing them through the registered
+// policies before delivery.
+//
+// Router is safe for concurrent use.
+type Router struct {
+	mu       sync.RWMutex
+	policies []Policy
+
+	// LoopDetector is used by Relay to detect relay loops of the segmented messages.
+	// Loop detection is disabled if nil.
+	LoopDetector *LoopDetector
+
+	// GTT resolves the destination of the messages routed on GT. The destinations
+	// that are inaccessible in PointCodeManager or prohibited in SSNStateManager are
+	// skipped, failing over to the next one in the order of priority. The routing
+	// falls back to the preferred one as soon as it becomes available again.
+	GTT              *GTT
+	SSNStateManager  *SSNStateManager
+	PointCodeManager *PointCodeManager
+
+	// Callbacks
+	OnReject   func(m Message, cause params.ReturnCauseValue)
+	OnFailover func(res *GTTResult, from, to Destination)
+}
+
+// NewRouter creates a new Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// AddPolicy registers a Policy. The policies are evaluated in the order of registration.
+func (r *Router) AddPolicy(p Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies = append(r.policies, p)
+}
+
+// AddPolicyFunc registers a function as a Policy.
+func (r *Router) AddPolicyFunc(f func(m Message, cdpa, cgpa *params.PartyAddress) PolicyVerdict) {
+	r.AddPolicy(PolicyFunc(f))
+}
+
+// Route routes the given message.
+//
+// The message is passed through the policies in order. A rejection stops the
+// evaluation, and a rewrite modifies the message in place so that the following
+// policies see the rewritten addresses.
+//
+// Only UDT and XUDT are routed. It returns ErrNotUnitdata for the others,
+// including the service messages UDTS and XUDTS.
+func (r *Router) Route(m Message) (*RouteResult, error) {
+	u, ok := m.(Unitdata)
+	if !ok || isServiceMessage(m) {
+		// the service messages are neither screened nor translated.
+		return nil, fmt.Errorf("%w: %s", ErrNotUnitdata, m.MessageTypeName())
+	}
+	cdpa, cgpa := u.CalledParty(), u.CallingParty()
+
+	r.mu.RLock()
+	policies := r.policies
+	r.mu.RUnlock()
+
+	for _, p := range policies {
+		v := p.Screen(m, cdpa, cgpa)
+		switch v.Action {
+		case PolicyActionAllow:
+		case PolicyActionReject:
+			return r.reject(m, v.ReturnCause), nil
+		case PolicyActionRewrite:
+			if v.CalledPartyAddress != nil {
+				cdpa = v.CalledPartyAddress
+			}
+			if v.CallingPartyAddress != nil {
+				cgpa = v.CallingPartyAddress
+			}
+			u.SetPartyAddresses(cdpa, cgpa)
+		default:
+			return nil, fmt.Errorf("unknown policy action: %s", v.Action)
+		}
+	}
+
+	if r.GTT == nil || cdpa == nil || cdpa.RouteOnSSN() {
+		return &RouteResult{Message: m}, nil
+	}
+
+	gtt, ok := r.GTT.TranslateAddress(cdpa)
+	if !ok {
+		return r.reject(m, params.ReturnCauseNoTranslationForThisSpecificAddress), nil
+	}
+	dest, cause, ok := r.selectDestination(gtt)
+	if !ok {
+		return r.reject(m, cause), nil
+	}
+
+	return &RouteResult{Message: m, Destination: &dest}, nil
+}
+
+// selectDestination returns the first available destination in res, or the cause
+// why none of them is available.
+func (r *Router) selectDestination(res *GTTResult) (Destination, params.ReturnCauseValue, bool) {
+	cause := params.ReturnCauseNoTranslationForThisSpecificAddress
+	for i, d := range res.Destinations {
+		if r.PointCodeManager != nil && !r.PointCodeManager.IsAccessible(d.PointCode) {
+			cause = params.ReturnCauseMTPFailure
+			continue
+		}
+		if !r.isSubsystemAllowed(d) {
+			cause = params.ReturnCauseSubsystemFailure
+			continue
+		}
+
+		if prev := int(res.active.Swap(int32(i))); prev != i {
+			var from Destination
+			if prev < len(res.Destinations) {
+				from = res.Destinations[prev]
+			}
+			logf("Switched destination for GT prefix %q: %s -> %s", res.Prefix, from, d)
+			if r.OnFailover != nil {
+				r.OnFailover(res, from, d)
+			}
+		}
+		return d, 0, true
+	}
+
+	return Destination{}, cause, false
+}
+
+// isSubsystemAllowed reports whether the subsystem in d is not prohibited.
+// The subsystems unknown to SSNStateManager are considered allowed.
+func (r *Router) isSubsystemAllowed(d Destination) bool {
+	if r.SSNStateManager == nil || d.SSN == 0 {
+		return true
+	}
+
+	entry := r.SSNStateManager.GetEntry(d.PointCode, d.SSN)
+	return entry == nil || entry.IsAllowed()
+}
+
+// Relay routes the given message that is to be relayed to another node.
+//
+// In addition to what Route does, it decrements the HopCounter in XUDT and checks
+// the relay loop with LoopDetector if set. The message is rejected with the hop
+// counter violation if the HopCounter reaches 0 or a probable loop is detected.
+func (r *Router) Relay(m Message) (*RouteResult, error) {
+	if x, ok := m.(*XUDT); ok {
+		if d := r.LoopDetector; d != nil && d.Check(x) {
+			return r.reject(m, params.ReturnCauseHopCounterViolation), nil
+		}
+
+		if x.HopCounter != nil && x.HopCounter.Decrement() == 0 {
+			return r.reject(m, params.ReturnCauseHopCounterViolation), nil
+		}
+	}
+
+	return r.Route(m)
+}
+
+func (r *Router) reject(m Message, cause params.ReturnCauseValue) *RouteResult {
+	logf("Rejected %s: cause=%s", m.MessageTypeName(), cause)
+	if r.OnReject != nil {
+		r.OnReject(m, cause)
+	}
+
+	return &RouteResult{
+		Message:     m,
+		Rejected:    true,
+		ReturnCause: cause,
+		Return:      newReturnMessage(m, cause),
+	}
+}
+
+// isServiceMessage reports whether m is a UDTS or XUDTS.
+func isServiceMessage(m Message) bool {
+	switch m.MessageType() {
+	case MsgTypeUDTS, MsgTypeXUDTS:
+		return true
+	default:
+		return false
+	}
+}
+
+// newReturnMessage creates the service message to return m to its originator,
+// or nil if the return option is not set in m. The service messages are never
+// returned.
+func newReturnMessage(m Message, cause params.ReturnCauseValue) Message {
+	var pcls *params.ProtocolClass
+	switch msg := m.(type) {
+	case *UDT:
+		pcls = msg.ProtocolClass
+	case *XUDT:
+		pcls = msg.ProtocolClass
+	default:
+		return nil
+	}
+	if pcls == nil || !pcls.ReturnOnError() {
+		return nil
+	}
+
+	u := m.(Unitdata)
+	if m.MessageType() == MsgTypeUDT {
+		return NewUDTS(cause, u.CallingParty(), u.CalledParty(), u.Payload())
+	}
+	return NewXUDTS(cause, DefaultHopCounter, u.CallingParty(), u.CalledParty(), u.Payload())
+}
@@ -0,0 +1,250 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+)
+
+func TestRouterPolicy(t *testing.T) {
+	r := sccp.NewRouter()
+	r.AddPolicyFunc(func(m sccp.Message, cdpa, cgpa *params.PartyAddress) sccp.PolicyVerdict {
+		if cgpa.GlobalTitle != nil && cgpa.Digits() == "1122334455" {
+			return sccp.PolicyReject(params.ReturnCauseUnqualified)
+		}
+		return sccp.PolicyAllow()
+	})
+	r.AddPolicyFunc(func(m sccp.Message, cdpa, cgpa *params.PartyAddress) sccp.PolicyVerdict {
+		if cdpa.SubsystemNumber != 6 {
+			return sccp.PolicyAllow()
+		}
+		rewritten := params.NewCalledPartyAddress(0x42, 0, 8, nil)
+		return sccp.PolicyRewrite(rewritten, nil)
+	})
+
+	t.Run("Rewrite", func(t *testing.T) {
+		x := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})
+		res, err := r.Route(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Rejected {
+			t.Fatalf("got rejected: %s", res)
+		}
+
+		b, err := res.Message.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := sccp.ParseXUDT(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := decoded.CalledPartyAddress.SubsystemNumber, uint8(8); got != want {
+			t.Errorf("got SSN %d, want %d", got, want)
+		}
+		if got, want := decoded.Data.Value(), []byte{0xde, 0xad, 0xbe, 0xef}; string(got) != string(want) {
+			t.Errorf("got data %x, want %x", got, want)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		x := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})
+		if err := x.CallingPartyAddress.SetDigits("1122334455"); err != nil {
+			t.Fatal(err)
+		}
+		x.CallingPartyAddress.SetLength()
+		x.SetPointers()
+
+		res, err := r.Route(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Rejected {
+			t.Fatal("got not rejected")
+		}
+
+		udts, ok := res.Return.(*sccp.XUDTS)
+		if !ok {
+			t.Fatalf("got %T, want *sccp.XUDTS", res.Return)
+		}
+		if got, want := udts.Cause(), params.ReturnCauseUnqualified; got != want {
+			t.Errorf("got cause %s, want %s", got, want)
+		}
+		if got, want := udts.CalledPartyAddress, x.CallingPartyAddress; got != want {
+			t.Errorf("got CdPA %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Not unitdata", func(t *testing.T) {
+		udts := sccp.NewUDTS(
+			params.ReturnCauseSubsystemFailure,
+			params.NewCalledPartyAddress(0x42, 0, 6, nil),
+			params.NewCallingPartyAddress(0x42, 0, 7, nil),
+			nil,
+		)
+		if _, err := r.Route(udts); !errors.Is(err, sccp.ErrNotUnitdata) {
+			t.Errorf("got %v, want %v", err, sccp.ErrNotUnitdata)
+		}
+	})
+}
+
+func TestRouterRelay(t *testing.T) {
+	newSegment := func() *sccp.XUDT {
+		x := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})
+		x.Segmentation = params.NewSegmentation(true, 0, 1, 0x123456)
+		x.EndOfOptionalParameters = params.NewEndOfOptionalParameters()
+		x.SetPointers()
+		return x
+	}
+
+	t.Run("Hop counter", func(t *testing.T) {
+		r := sccp.NewRouter()
+		x := newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef}) // Hop Counter = 2
+
+		res, err := r.Relay(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Rejected {
+			t.Fatalf("got rejected: %s", res)
+		}
+		if got, want := x.HopCounter.Value(), uint8(1); got != want {
+			t.Errorf("got hop counter %d, want %d", got, want)
+		}
+
+		res, err = r.Relay(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := res.ReturnCause, params.ReturnCauseHopCounterViolation; !res.Rejected || got != want {
+			t.Errorf("got %s, want rejected (%s)", res, want)
+		}
+	})
+
+	t.Run("Loop", func(t *testing.T) {
+		var loops int
+		r := sccp.NewRouter()
+		r.LoopDetector = sccp.NewLoopDetector()
+		r.LoopDetector.OnLoop = func(m sccp.Message, key sccp.LoopKey, count int) {
+			loops++
+		}
+
+		res, err := r.Relay(newSegment())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Rejected {
+			t.Fatalf("got rejected: %s", res)
+		}
+
+		// the same segment comes back with a different hop counter.
+		looped := newSegment()
+		looped.HopCounter = params.NewHopCounter(10)
+		res, err = r.Relay(looped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := res.ReturnCause, params.ReturnCauseHopCounterViolation; !res.Rejected || got != want {
+			t.Errorf("got %s, want rejected (%s)", res, want)
+		}
+		if _, ok := res.Return.(*sccp.XUDTS); !ok {
+			t.Errorf("got %T, want *sccp.XUDTS", res.Return)
+		}
+		if got, want := loops, 1; got != want {
+			t.Errorf("got %d loops, want %d", got, want)
+		}
+
+		// unsegmented messages are not tracked.
+		if r.LoopDetector.Check(newTestXUDT(nil)) {
+			t.Error("got unsegmented message looping")
+		}
+		if got, want := r.LoopDetector.Len(), 1; got != want {
+			t.Errorf("got %d entries, want %d", got, want)
+		}
+	})
+
+	t.Run("Loop with zero value", func(t *testing.T) {
+		d := &sccp.LoopDetector{}
+		if d.Check(newSegment()) {
+			t.Error("got the first segment looping")
+		}
+		if !d.Check(newSegment()) {
+			t.Error("got the same segment not looping")
+		}
+		if got, want := d.Len(), 1; got != want {
+			t.Errorf("got %d entries, want %d", got, want)
+		}
+	})
+}
+
+func TestRouterFailover(t *testing.T) {
+	primary := sccp.Destination{PointCode: 100, SSN: 6}
+	backup := sccp.Destination{PointCode: 200, SSN: 6}
+
+	var switched []sccp.Destination
+	r := sccp.NewRouter()
+	r.GTT = sccp.NewGTT()
+	r.GTT.Add("1234", primary, backup)
+	r.SSNStateManager = sccp.NewSSNStateManager()
+	r.PointCodeManager = sccp.NewPointCodeManager()
+	r.OnFailover = func(res *sccp.GTTResult, from, to sccp.Destination) {
+		switched = append(switched, to)
+	}
+
+	route := func(t *testing.T) *sccp.RouteResult {
+		t.Helper()
+		res, err := r.Route(newTestXUDT([]byte{0xde, 0xad, 0xbe, 0xef})) // CdGT: 123456789012345
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	wantDestination := func(t *testing.T, res *sccp.RouteResult, want sccp.Destination) {
+		t.Helper()
+		if res.Rejected || res.Destination == nil {
+			t.Fatalf("got %s, want delivered to %s", res, want)
+		}
+		if got := *res.Destination; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	}
+
+	wantDestination(t, route(t), primary)
+
+	// SSP from the primary: fail over to the backup.
+	if err := r.SSNStateManager.HandleSSP(primary.PointCode, primary.SSN); err != nil {
+		t.Fatal(err)
+	}
+	wantDestination(t, route(t), backup)
+
+	// MTP-PAUSE for the backup: nowhere to go.
+	r.PointCodeManager.HandlePause(backup.PointCode)
+	if res := route(t); !res.Rejected || res.ReturnCause != params.ReturnCauseMTPFailure {
+		t.Errorf("got %s, want rejected (%s)", res, params.ReturnCauseMTPFailure)
+	}
+
+	// MTP-RESUME for the backup, then SSA from the primary: fail back to the primary.
+	r.PointCodeManager.HandleResume(backup.PointCode)
+	wantDestination(t, route(t), backup)
+	if err := r.SSNStateManager.HandleSSA(primary.PointCode, primary.SSN); err != nil {
+		t.Fatal(err)
+	}
+	wantDestination(t, route(t), primary)
+
+	if got, want := switched, []sccp.Destination{backup, primary}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got switched to %v, want %v", got, want)
+	}
+
+	// no translation for the GT.
+	r.GTT.Remove("1234")
+	if res := route(t); !res.Rejected || res.ReturnCause != params.ReturnCauseNoTranslationForThisSpecificAddress {
+		t.Errorf("got %s, want rejected (%s)", res, params.ReturnCauseNoTranslationForThisSpecificAddress)
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"io"
 	"sync"
 	"time"
+
+	"github.com/cgngc/go-sccp/params"
 )
 
 // MsgType is type of SCCP message.
@@ -75,7 +77,7 @@ const (
 // SSNEntry represents a subsystem entry with state management
 type SSNEntry struct {
 	SSN             uint8
-	PointCode       uint16
+	PointCode       params.PointCode
 	State           SSNState
 	IsLocal         bool
 	LastStateChange time.Time
@@ -118,6 +120,10 @@ type SSNStateManager struct {
 	entries map[string]*SSNEntry // key: "pc:ssn"
 	mutex   sync.RWMutex
 
+	// send is set by the Stack using the SSNStateManager to send SST from pc.
+	send func(m Message) error
+	pc   params.PointCode
+
 	// Configuration
 	DefaultTestInterval time.Duration
 	MaxTestInterval     time.Duration
@@ -137,17 +143,17 @@ func NewSSNStateManager() *SSNStateManager {
 	}
 }
 
-func (sm *SSNStateManager) getKey(pc uint16, ssn uint8) string {
+func (sm *SSNStateManager) getKey(pc params.PointCode, ssn uint8) string {
 	return fmt.Sprintf("%d:%d", pc, ssn)
 }
 
-func (sm *SSNStateManager) GetEntry(pc uint16, ssn uint8) *SSNEntry {
+func (sm *SSNStateManager) GetEntry(pc params.PointCode, ssn uint8) *SSNEntry {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 	return sm.entries[sm.getKey(pc, ssn)]
 }
 
-func (sm *SSNStateManager) AddEntry(pc uint16, ssn uint8, isLocal bool) *SSNEntry {
+func (sm *SSNStateManager) AddEntry(pc params.PointCode, ssn uint8, isLocal bool) *SSNEntry {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
@@ -177,8 +183,28 @@ type Message interface {
 	fmt.Stringer
 }
 
+// Unitdata is an interface that defines the connectionless SCCP messages carrying
+// the user data between the Called and Calling Party Address, i.e., UDT, XUDT and
+// their service messages UDTS and XUDTS.
+//
+// The routing, logging and screening code can be written against Unitdata without
+// type-switching on every message type.
+type Unitdata interface {
+	Message
+	CalledParty() *params.PartyAddress
+	CallingParty() *params.PartyAddress
+	Payload() []byte
+
+	// SetPartyAddresses replaces the Called and Calling Party Address, keeping the
+	// message consistent for serialization.
+	SetPartyAddresses(cdpa, cgpa *params.PartyAddress)
+}
+
 // ParseMessage decodes the byte sequence into Message by Message Type.
-func ParseMessage(b []byte) (Message, error) {
+//
+// The Data in the Message refers to b without copying by default. Give
+// params.WithOwnedData to make a copy, e.g., when b is a pooled buffer.
+func ParseMessage(b []byte, opts ...params.DataOption) (Message, error) {
 	if len(b) < 1 {
 		return nil, fmt.Errorf("invalid SCCP message %v: %w", b, io.ErrUnexpectedEOF)
 	}
@@ -196,18 +222,9 @@ func ParseMessage(b []byte) (Message, error) {
 	*/
 	case MsgTypeUDT:
 		m = &UDT{}
-
-		if err := m.UnmarshalBinary(b); err != nil {
-			return nil, fmt.Errorf("failed to parse UDT message: %w", err)
-		}
-
-		//validation check
-		/*if udt, ok := m.(*UDT); ok && !udt.IsValidForProcessing() {
-			return nil, fmt.Errorf("UDT message has invalid protocol class")
-		}*/
-
-	/* TODO: implement!
 	case MsgTypeUDTS:
+		m = &UDTS{}
+	/* TODO: implement!
 	case MsgTypeED:
 	case MsgTypeEA:
 	case MsgTypeRSR:
@@ -217,8 +234,9 @@ func ParseMessage(b []byte) (Message, error) {
 	*/
 	case MsgTypeXUDT:
 		m = &XUDT{}
-	/* TODO: implement!
 	case MsgTypeXUDTS:
+		m = &XUDTS{}
+	/* TODO: implement!
 	case MsgTypeLUDT:
 	case MsgTypeLUDTS:
 	*/
@@ -229,8 +247,27 @@ func ParseMessage(b []byte) (Message, error) {
 	if err := m.UnmarshalBinary(b); err != nil {
 		return nil, err
 	}
+	applyDataOptions(m, opts)
 	return m, nil
 }
 
+// applyDataOptions applies opts to the Data in m if m has one.
+func applyDataOptions(m Message, opts []params.DataOption) {
+	if len(opts) == 0 {
+		return
+	}
+
+	switch m := m.(type) {
+	case *UDT:
+		m.Data.Apply(opts...)
+	case *UDTS:
+		m.Data.Apply(opts...)
+	case *XUDT:
+		m.Data.Apply(opts...)
+	case *XUDTS:
+		m.Data.Apply(opts...)
+	}
+}
+
 // Global state manager instance
 var DefaultSSNStateManager = NewSSNStateManager()
@@ -181,6 +181,48 @@ var testcases = []struct {
 			return sccp.ParseXUDT(b)
 		},
 	},
+	{
+		description: "UDTS",
+		structured: sccp.NewUDTS(
+			params.ReturnCauseSubsystemFailure,
+			params.NewCalledPartyAddress(0x42, 0, 6, nil),
+			params.NewCallingPartyAddress(0x42, 0, 7, nil),
+			[]byte{0xde, 0xad, 0xbe, 0xef},
+		),
+		serialized: []byte{
+			0x0a,             // MsgType
+			0x03,             // Return Cause
+			0x03, 0x05, 0x07, // Pointers
+			0x02, 0x42, 0x06, // CdPA
+			0x02, 0x42, 0x07, // CgPA
+			0x04, 0xde, 0xad, 0xbe, 0xef, // Data
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return sccp.ParseUDTS(b)
+		},
+	},
+	{
+		description: "XUDTS",
+		structured: sccp.NewXUDTS(
+			params.ReturnCauseSubsystemFailure,
+			15,
+			params.NewCalledPartyAddress(0x42, 0, 6, nil),
+			params.NewCallingPartyAddress(0x42, 0, 7, nil),
+			[]byte{0xde, 0xad, 0xbe, 0xef},
+		),
+		serialized: []byte{
+			0x12,                   // MsgType
+			0x03,                   // Return Cause
+			0x0f,                   // Hop Counter
+			0x04, 0x06, 0x08, 0x00, // Pointers
+			0x02, 0x42, 0x06, // CdPA
+			0x02, 0x42, 0x07, // CgPA
+			0x04, 0xde, 0xad, 0xbe, 0xef, // Data
+		},
+		parseFunc: func(b []byte) (serializable, error) {
+			return sccp.ParseXUDTS(b)
+		},
+	},
 	{
 		description: "SCMG SSA",
 		structured:  sccp.NewSCMG(sccp.SCMGTypeSSA, 9, 405, 0, 0),
@@ -277,3 +319,75 @@ func TestPartialStructuredMessages(t *testing.T) {
 		}
 	}
 }
+
+func TestUnitdata(t *testing.T) {
+	cdpa := params.NewCalledPartyAddress(0x42, 0, 6, nil)
+	cgpa := params.NewCallingPartyAddress(0x42, 0, 7, nil)
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	msgs := []sccp.Unitdata{
+		sccp.NewUDT(1, true, cdpa, cgpa, data),
+		sccp.NewXUDT(1, true, 15, cdpa, cgpa, data),
+		sccp.NewUDTS(params.ReturnCauseSubsystemFailure, cdpa, cgpa, data),
+		sccp.NewXUDTS(params.ReturnCauseSubsystemFailure, 15, cdpa, cgpa, data),
+	}
+
+	for _, m := range msgs {
+		t.Run(m.MessageTypeName(), func(t *testing.T) {
+			if m.CalledParty() != cdpa || m.CallingParty() != cgpa {
+				t.Errorf("got %v, %v, want %v, %v", m.CalledParty(), m.CallingParty(), cdpa, cgpa)
+			}
+			if got := m.Payload(); string(got) != string(data) {
+				t.Errorf("got %x, want %x", got, data)
+			}
+
+			m.SetPartyAddresses(cgpa, cdpa)
+			if m.CalledParty() != cgpa || m.CallingParty() != cdpa {
+				t.Errorf("got %v, %v, want %v, %v", m.CalledParty(), m.CallingParty(), cgpa, cdpa)
+			}
+		})
+	}
+}
+
+func TestParseMessageOwnedData(t *testing.T) {
+	cdpa := params.NewCalledPartyAddress(0x42, 0, 6, nil)
+	cgpa := params.NewCallingPartyAddress(0x42, 0, 7, nil)
+	data := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	msgs := []sccp.Unitdata{
+		sccp.NewUDT(1, true, cdpa, cgpa, data),
+		sccp.NewXUDT(1, true, 15, cdpa, cgpa, data),
+		sccp.NewUDTS(params.ReturnCauseSubsystemFailure, cdpa, cgpa, data),
+		sccp.NewXUDTS(params.ReturnCauseSubsystemFailure, 15, cdpa, cgpa, data),
+	}
+
+	for _, m := range msgs {
+		t.Run(m.MessageTypeName(), func(t *testing.T) {
+			b, err := m.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			aliased, err := sccp.ParseMessage(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			owned, err := sccp.ParseMessage(b, params.WithOwnedData())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the buffer is reused for another message.
+			for i := range b {
+				b[i] = 0
+			}
+
+			if got := aliased.(sccp.Unitdata).Payload(); string(got) == string(data) {
+				t.Errorf("got %x, want the Data to refer to the buffer", got)
+			}
+			if got := owned.(sccp.Unitdata).Payload(); string(got) != string(data) {
+				t.Errorf("got %x, want %x", got, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+// Package sccptest provides utilities for testing the SCCP entities in-process,
+// without SCTP/M3UA.
+package sccptest
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoopbackConfig is a configuration of the network simulated by Loopback.
+type LoopbackConfig struct {
+	// Latency is the delay of every message, plus a random delay up to Jitter.
+	// The messages are delivered in order unless reordered explicitly.
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// LossRate is the probability (0-1) that a message is dropped.
+	LossRate float64
+
+	// ReorderRate is the probability (0-1) that a message is held back and delivered
+	// after the next message written to the same end.
+	ReorderRate float64
+
+	// QueueSize is the number of messages that can be in flight in each direction.
+	// Write blocks when the queue is full.
+	QueueSize int
+
+	// Seed is the seed of the random source for the loss, reordering and jitter.
+	Seed int64
+}
+
+// LoopbackStats is the statistics of the messages written to a LoopbackTransport.
+type LoopbackStats struct {
+	Written   uint64
+	Dropped   uint64
+	Reordered uint64
+}
+
+type delivery struct {
+	at time.Time
+	b  []byte
+}
+
+// LoopbackTransport is an end of the in-memory connection created by NewLoopback.
+// It satisfies sccp.Transport.
+type LoopbackTransport struct {
+	cfg  LoopbackConfig
+	peer *LoopbackTransport
+
+	mu   sync.Mutex
+	rnd  *rand.Rand
+	held []byte
+
+	// messages in flight from this end, and the ones arrived at this end.
+	pipe chan delivery
+	rx   chan []byte
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	written, dropped, reordered atomic.Uint64
+}
+
+// NewLoopback creates a pair of LoopbackTransport connected with each other.
+func NewLoopback(cfg LoopbackConfig) (*LoopbackTransport, *LoopbackTransport) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+
+	a := newLoopbackTransport(cfg, cfg.Seed)
+	b := newLoopbackTransport(cfg, cfg.Seed+1)
+	a.peer, b.peer = b, a
+
+	go a.run()
+	go b.run()
+	return a, b
+}
+
+func newLoopbackTransport(cfg LoopbackConfig, seed int64) *LoopbackTransport {
+	return &LoopbackTransport{
+		cfg:  cfg,
+		rnd:  rand.New(rand.NewSource(seed)),
+		pipe: make(chan delivery, cfg.QueueSize),
+		rx:   make(chan []byte, cfg.QueueSize),
+		done: make(chan struct{}),
+	}
+}
+
+// Write sends a copy of b to the peer.
+//
+// It never fails due to the simulated loss; the dropped messages are just counted
+// in Stats.
+func (t *LoopbackTransport) Write(b []byte) (int, error) {
+	select {
+	case <-t.done:
+		return 0, net.ErrClosed
+	default:
+	}
+	t.written.Add(1)
+
+	msg := make([]byte, len(b))
+	copy(msg, b)
+
+	t.mu.Lock()
+	if t.cfg.LossRate > 0 && t.rnd.Float64() < t.cfg.LossRate {
+		t.mu.Unlock()
+		t.dropped.Add(1)
+		return len(b), nil
+	}
+
+	var msgs [][]byte
+	switch {
+	case t.held != nil:
+		msgs = [][]byte{msg, t.held}
+		t.held = nil
+	case t.cfg.ReorderRate > 0 && t.rnd.Float64() < t.cfg.ReorderRate:
+		t.held = msg
+		t.reordered.Add(1)
+	default:
+		msgs = [][]byte{msg}
+	}
+
+	now := time.Now()
+	deliveries := make([]delivery, len(msgs))
+	for i, m := range msgs {
+		delay := t.cfg.Latency
+		if t.cfg.Jitter > 0 {
+			delay += time.Duration(t.rnd.Int63n(int64(t.cfg.Jitter)))
+		}
+		deliveries[i] = delivery{at: now.Add(delay), b: m}
+	}
+	t.mu.Unlock()
+
+	for _, d := range deliveries {
+		select {
+		case t.pipe <- d:
+		case <-t.done:
+			return 0, net.ErrClosed
+		}
+	}
+	return len(b), nil
+}
+
+// Read reads a message sent by the peer into b.
+//
+// It returns io.ErrShortBuffer if b is smaller than the message, and net.ErrClosed
+// after Close is called.
+func (t *LoopbackTransport) Read(b []byte) (int, error) {
+	select {
+	case msg := <-t.rx:
+		if len(b) < len(msg) {
+			return 0, io.ErrShortBuffer
+		}
+		return copy(b, msg), nil
+	case <-t.done:
+		return 0, net.ErrClosed
+	}
+}
+
+// Close closes this end of the connection. The messages sent to the closed end
+// are discarded.
+func (t *LoopbackTransport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	return nil
+}
+
+// Stats returns the statistics of the messages written to this end.
+func (t *LoopbackTransport) Stats() LoopbackStats {
+	return LoopbackStats{
+		Written:   t.written.Load(),
+		Dropped:   t.dropped.Load(),
+		Reordered: t.reordered.Load(),
+	}
+}
+
+// run delivers the messages in flight to the peer in order, after their delay.
+func (t *LoopbackTransport) run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		var d delivery
+		select {
+		case d = <-t.pipe:
+		case <-t.done:
+			return
+		}
+
+		if wait := time.Until(d.at); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-t.done:
+				return
+			}
+		}
+
+		select {
+		case t.peer.rx <- d.b:
+		case <-t.peer.done:
+		case <-t.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccptest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+)
+
+// Pair is a pair of sccp.Stack connected with each other by NewLoopback.
+//
+// The messages delivered to the stacks are recorded in AReceived and BReceived
+// unless OnMessage is replaced before Start. RetainMessages is set in both stacks
+// for the Recorder.
+type Pair struct {
+	A, B                   *sccp.Stack
+	ATransport, BTransport *LoopbackTransport
+	AReceived, BReceived   *Recorder
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	serveErr error
+}
+
+// NewPair creates a new Pair of stacks with the given point codes. Call Start to
+// make them serve.
+func NewPair(pcA, pcB params.PointCode, cfg LoopbackConfig) *Pair {
+	ta, tb := NewLoopback(cfg)
+	p := &Pair{
+		A:          sccp.NewStack(pcA, ta),
+		B:          sccp.NewStack(pcB, tb),
+		ATransport: ta,
+		BTransport: tb,
+		AReceived:  NewRecorder(),
+		BReceived:  NewRecorder(),
+	}
+	p.A.OnMessage = p.AReceived.Handle
+	p.B.OnMessage = p.BReceived.Handle
+	p.A.RetainMessages = true
+	p.B.RetainMessages = true
+
+	return p
+}
+
+// Start makes both stacks serve in background.
+func (p *Pair) Start() {
+	for _, s := range []*sccp.Stack{p.A, p.B} {
+		p.wg.Add(1)
+		go func(s *sccp.Stack) {
+			defer p.wg.Done()
+			if err := s.Serve(); err != nil {
+				p.mu.Lock()
+				if p.serveErr == nil {
+					p.serveErr = fmt.Errorf("stack PC=%s stopped serving: %w", s.PointCode, err)
+				}
+				p.mu.Unlock()
+			}
+		}(s)
+	}
+}
+
+// Close closes both stacks and waits for them to stop serving. It returns the
+// error if any of the stacks stopped serving unexpectedly.
+func (p *Pair) Close() error {
+	errA := p.A.Close()
+	errB := p.B.Close()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, err := range []error{p.serveErr, errA, errB} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Recorder records the messages delivered to a sccp.Stack.
+//
+// Recorder is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	msgs   []sccp.Message
+	notify chan struct{}
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Handle records m. Set it to sccp.Stack.OnMessage, with sccp.Stack.RetainMessages
+// set.
+func (r *Recorder) Handle(m sccp.Message) {
+	r.mu.Lock()
+	r.msgs = append(r.msgs, m)
+	r.mu.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Messages returns the messages recorded so far.
+func (r *Recorder) Messages() []sccp.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]sccp.Message(nil), r.msgs...)
+}
+
+// Wait waits until n messages are recorded and returns them. It returns error
+// with the messages recorded so far if timeout expires.
+func (r *Recorder) Wait(n int, timeout time.Duration) ([]sccp.Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		msgs := r.Messages()
+		if len(msgs) >= n {
+			return msgs, nil
+		}
+
+		select {
+		case <-r.notify:
+		case <-timer.C:
+			return msgs, fmt.Errorf("got %d messages in %s, want %d", len(msgs), timeout, n)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccptest_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/cgngc/go-sccp/sccptest"
+)
+
+func newXUDT(seq byte) *sccp.XUDT {
+	return sccp.NewXUDT(
+		1, true, sccp.DefaultHopCounter,
+		params.NewCalledPartyAddress(0x42, 0, 6, nil),
+		params.NewCallingPartyAddress(0x42, 0, 7, nil),
+		[]byte{seq},
+	)
+}
+
+func sequence(t *testing.T, msgs []sccp.Message) []byte {
+	t.Helper()
+
+	var seq []byte
+	for _, m := range msgs {
+		x, ok := m.(*sccp.XUDT)
+		if !ok {
+			t.Fatalf("got %T, want *sccp.XUDT", m)
+		}
+		seq = append(seq, x.Data.Value()...)
+	}
+	return seq
+}
+
+func TestPair(t *testing.T) {
+	cases := []struct {
+		description string
+		cfg         sccptest.LoopbackConfig
+		send        int
+		want        []byte
+	}{
+		{
+			"No impairment",
+			sccptest.LoopbackConfig{},
+			3, []byte{0, 1, 2},
+		},
+		{
+			"Latency",
+			sccptest.LoopbackConfig{Latency: 5 * time.Millisecond, Jitter: time.Millisecond},
+			3, []byte{0, 1, 2},
+		},
+		{
+			"Loss",
+			sccptest.LoopbackConfig{LossRate: 1},
+			3, nil,
+		},
+		{
+			"Reorder",
+			sccptest.LoopbackConfig{ReorderRate: 1},
+			4, []byte{1, 0, 3, 2},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			p := sccptest.NewPair(1, 2, c.cfg)
+			p.Start()
+			defer func() {
+				if err := p.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+
+			for i := 0; i < c.send; i++ {
+				if err := p.A.Send(newXUDT(byte(i))); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			msgs, err := p.BReceived.Wait(len(c.want), time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sequence(t, msgs); string(got) != string(c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+
+			if got, want := p.ATransport.Stats().Written, uint64(c.send); got != want {
+				t.Errorf("got %d written, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestPairReturn(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{Latency: time.Millisecond})
+	p.B.Router = sccp.NewRouter()
+	p.B.Router.AddPolicyFunc(func(m sccp.Message, cdpa, cgpa *params.PartyAddress) sccp.PolicyVerdict {
+		return sccp.PolicyReject(params.ReturnCauseUnequippedUser)
+	})
+	p.Start()
+	defer p.Close()
+
+	if err := p.A.Send(newXUDT(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := p.AReceived.Wait(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xudts, ok := msgs[0].(*sccp.XUDTS)
+	if !ok {
+		t.Fatalf("got %T, want *sccp.XUDTS", msgs[0])
+	}
+	if got, want := xudts.Cause(), params.ReturnCauseUnequippedUser; got != want {
+		t.Errorf("got cause %s, want %s", got, want)
+	}
+	if got := len(p.BReceived.Messages()); got != 0 {
+		t.Errorf("got %d messages delivered to B, want 0", got)
+	}
+}
+
+func TestPairSCMG(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	changed := make(chan *sccp.SSNEntry, 1)
+	p.B.SSNStateManager.OnStateChange = func(e *sccp.SSNEntry, s sccp.SSNState, r sccp.StateChangeReason) {
+		changed <- e
+	}
+	p.Start()
+	defer p.Close()
+
+	udt, err := sccp.WrapSCMG(sccp.NewSCMG(sccp.SCMGTypeSSA, 6, 1, 0, 0), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.A.Send(udt); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-changed:
+		if e.PointCode != 1 || e.SSN != 6 || !e.IsAllowed() {
+			t.Errorf("got PC=%s, SSN=%d, allowed=%v, want PC=1, SSN=6 allowed", e.PointCode, e.SSN, e.IsAllowed())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SSA not processed")
+	}
+
+	if got := len(p.BReceived.Messages()); got != 0 {
+		t.Errorf("got %d messages delivered to B, want 0", got)
+	}
+}
+
+func TestPairSST(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	p.A.SSNStateManager.DefaultTestInterval = 10 * time.Millisecond
+	changed := make(chan sccp.SSNState, 3)
+	p.A.SSNStateManager.OnStateChange = func(e *sccp.SSNEntry, s sccp.SSNState, r sccp.StateChangeReason) {
+		changed <- s
+	}
+	// B records the SCMG messages instead of processing them.
+	p.B.SSNStateManager = nil
+	p.Start()
+	defer p.Close()
+
+	send := func(typ sccp.SCMGType) {
+		t.Helper()
+		udt, err := sccp.WrapSCMG(sccp.NewSCMG(typ, 6, 2, 0, 0), 2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.B.Send(udt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send(sccp.SCMGTypeSSA)
+	send(sccp.SCMGTypeSSP)
+
+	msgs, err := p.BReceived.Wait(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sst, ok := sccp.ExtractSCMG(msgs[0])
+	if !ok {
+		t.Fatalf("got %v, want SST", msgs[0])
+	}
+	if sst.Type != sccp.SCMGTypeSST || sst.AffectedPC != 2 || sst.AffectedSSN != 6 {
+		t.Errorf("got %s, want SST for PC=2, SSN=6", sst)
+	}
+	if got, want := msgs[0].(*sccp.UDT).CallingPartyAddress.SignalingPointCode, params.PointCode(1); got != want {
+		t.Errorf("got SST from PC=%s, want %s", got, want)
+	}
+
+	send(sccp.SCMGTypeSSA)
+	for _, want := range []sccp.SSNState{sccp.SSNStateAllowed, sccp.SSNStateProhibited, sccp.SSNStateAllowed} {
+		select {
+		case got := <-changed:
+			if got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("state not changed to %v", want)
+		}
+	}
+}
+
+type traceRecorder struct {
+	mu      sync.Mutex
+	in, out []sccp.TraceEvent
+}
+
+func (r *traceRecorder) OnMessageIn(e *sccp.TraceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.in = append(r.in, retain(e))
+}
+
+func (r *traceRecorder) OnMessageOut(e *sccp.TraceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.out = append(r.out, retain(e))
+}
+
+// retain returns a copy of e with Raw copied, which is valid only during the call
+// to the Tracer.
+func retain(e *sccp.TraceEvent) sccp.TraceEvent {
+	c := *e
+	c.Raw = append([]byte(nil), e.Raw...)
+	return c
+}
+
+func TestPairTrace(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	p.B.Router = sccp.NewRouter()
+	p.B.Router.AddPolicyFunc(func(m sccp.Message, cdpa, cgpa *params.PartyAddress) sccp.PolicyVerdict {
+		if m.(*sccp.XUDT).Data.Value()[0] == 1 {
+			return sccp.PolicyReject(params.ReturnCauseUnequippedUser)
+		}
+		return sccp.PolicyAllow()
+	})
+
+	ta, tb := &traceRecorder{}, &traceRecorder{}
+	stats := sccp.NewTraceStats()
+	p.A.Tracer = ta
+	p.B.Tracer = sccp.MultiTracer(tb, stats)
+	p.Start()
+	defer p.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := p.A.Send(newXUDT(byte(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := p.AReceived.Wait(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if got, want := len(ta.out), 2; got != want {
+		t.Fatalf("got %d messages traced out of A, want %d", got, want)
+	}
+	for i, e := range ta.out {
+		if got, want := e.Seq, uint64(i+1); got != want {
+			t.Errorf("got Seq %d, want %d", got, want)
+		}
+	}
+
+	if got, want := len(tb.in), 2; got != want {
+		t.Fatalf("got %d messages traced into B, want %d", got, want)
+	}
+	if tb.in[0].Route == nil || tb.in[0].Route.Rejected {
+		t.Errorf("got route %v for #1, want delivered", tb.in[0].Route)
+	}
+	if tb.in[1].Route == nil || !tb.in[1].Route.Rejected {
+		t.Errorf("got route %v for #2, want rejected", tb.in[1].Route)
+	}
+	if got, want := len(tb.out), 1; got != want {
+		t.Fatalf("got %d messages traced out of B, want %d", got, want)
+	}
+	if _, ok := tb.out[0].Message.(*sccp.XUDTS); !ok {
+		t.Errorf("got %T traced out of B, want *sccp.XUDTS", tb.out[0].Message)
+	}
+
+	if got, want := len(ta.in), 1; got != want {
+		t.Fatalf("got %d messages traced into A, want %d", got, want)
+	}
+	if got, want := ta.in[0].Raw, tb.out[0].Raw; string(got) != string(want) {
+		t.Errorf("got %x traced into A, want %x", got, want)
+	}
+
+	peer := stats.Peers()["SSN:7"]
+	if got, want := peer, (sccp.PeerStats{In: 2, Out: 1, Rejected: 1, LastIn: tb.in[1].Time, LastOut: tb.out[0].Time}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestPairTracePeerSeq(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	ta, tb := &traceRecorder{}, &traceRecorder{}
+	p.A.Tracer = ta
+	p.B.Tracer = tb
+	p.Start()
+	defer p.Close()
+
+	for _, ssn := range []uint8{6, 8, 6} {
+		x := newXUDT(ssn)
+		x.CalledPartyAddress = params.NewCalledPartyAddress(0x42, 0, ssn, nil)
+		if err := p.A.Send(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := p.BReceived.Wait(3, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	type seq struct {
+		Seq     uint64
+		Peer    string
+		PeerSeq uint64
+	}
+	for _, c := range []struct {
+		description string
+		events      []sccp.TraceEvent
+		want        []seq
+	}{
+		{"Out of A", ta.out, []seq{{1, "SSN:6", 1}, {2, "SSN:8", 1}, {3, "SSN:6", 2}}},
+		{"Into B", tb.in, []seq{{1, "SSN:7", 1}, {2, "SSN:7", 2}, {3, "SSN:7", 3}}},
+	} {
+		if len(c.events) != len(c.want) {
+			t.Fatalf("%s: got %d events, want %d", c.description, len(c.events), len(c.want))
+		}
+		for i, e := range c.events {
+			if got := (seq{e.Seq, e.Peer, e.PeerSeq}); got != c.want[i] {
+				t.Errorf("%s: got %+v, want %+v", c.description, got, c.want[i])
+			}
+		}
+	}
+}
+
+func TestPairReassembly(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	notices := make(chan *sccp.Notice, 1)
+	p.B.Reassembler = sccp.NewReassembler()
+	p.B.Reassembler.Timeout = 20 * time.Millisecond
+	p.B.Reassembler.OnNotice = func(n *sccp.Notice) {
+		notices <- n
+	}
+	p.Start()
+	defer p.Close()
+
+	segment := func(first bool, rem uint8, lrn uint32, data byte) *sccp.XUDT {
+		x := newXUDT(data)
+		x.Segmentation = params.NewSegmentation(first, 1, rem, lrn)
+		x.SetPointers()
+		return x
+	}
+
+	for _, x := range []*sccp.XUDT{
+		segment(true, 1, 1, 0x01),
+		segment(false, 0, 1, 0x02),
+		segment(true, 1, 2, 0x11),
+	} {
+		if err := p.A.Send(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs, err := p.BReceived.Wait(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sequence(t, msgs); string(got) != "\x01\x02" {
+		t.Errorf("got %x, want 0102", got)
+	}
+	if x := msgs[0].(*sccp.XUDT); x.Segmentation != nil {
+		t.Errorf("got %v delivered, want no Segmentation", x.Segmentation)
+	}
+
+	select {
+	case n := <-notices:
+		if n.Reason != sccp.NoticeReasonTimeout || string(n.Data) != "\x11" {
+			t.Errorf("got %s, want timeout with 11", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("T(reass) not expired")
+	}
+
+	msgs, err = p.AReceived.Wait(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xudts, ok := msgs[0].(*sccp.XUDTS)
+	if !ok {
+		t.Fatalf("got %T, want *sccp.XUDTS", msgs[0])
+	}
+	if got, want := xudts.Cause(), params.ReturnCauseSegmentationFailure; got != want {
+		t.Errorf("got cause %s, want %s", got, want)
+	}
+}
+
+func TestPairPayloadHandler(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	pdus := make(chan any, 1)
+	p.B.PayloadHandler = sccp.NewPayloadHandler(
+		sccp.PayloadParserFunc(func(b []byte) (any, error) {
+			return string(b), nil
+		}),
+		func(m sccp.Message, pdu any) {
+			pdus <- pdu
+		},
+	)
+	p.Start()
+	defer p.Close()
+
+	if err := p.A.Send(newXUDT(0x62)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case pdu := <-pdus:
+		if pdu != "\x62" {
+			t.Errorf("got %v, want 62", pdu)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("payload not handled")
+	}
+
+	// OnMessage still gets the message.
+	if _, err := p.BReceived.Wait(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPairRouteError(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	p.B.Router = sccp.NewRouter()
+	p.B.Router.AddPolicyFunc(func(m sccp.Message, cdpa, cgpa *params.PartyAddress) sccp.PolicyVerdict {
+		return sccp.PolicyVerdict{Action: sccp.PolicyAction(0xff)}
+	})
+	errs := make(chan error, 1)
+	p.B.OnError = func(err error) {
+		errs <- err
+	}
+	p.Start()
+	defer p.Close()
+
+	if err := p.A.Send(newXUDT(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		if errors.Is(err, sccp.ErrNotUnitdata) {
+			t.Errorf("got %v, want unknown policy action", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("route error not reported")
+	}
+
+	if got := len(p.BReceived.Messages()); got != 0 {
+		t.Errorf("got %d messages delivered to B, want 0", got)
+	}
+}
+
+func TestPairTraceSendError(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	tr := &traceRecorder{}
+	p.A.Tracer = tr
+	p.A.Limits = sccp.Limits{MaxMessageLength: 8}
+	p.Start()
+	defer p.Close()
+
+	x := newXUDT(0)
+	if err := p.A.Send(x); err == nil {
+		t.Fatal("got no error")
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if got, want := len(tr.out), 1; got != want {
+		t.Fatalf("got %d messages traced out of A, want %d", got, want)
+	}
+	var lerr *sccp.LengthError
+	if e := tr.out[0]; e.Message != x || e.Raw != nil || !errors.As(e.Err, &lerr) {
+		t.Errorf("got %s, want the XUDT with *sccp.LengthError", &e)
+	}
+}
+
+func TestPairReassemblyLongData(t *testing.T) {
+	p := sccptest.NewPair(1, 2, sccptest.LoopbackConfig{})
+	p.B.Reassembler = sccp.NewReassembler()
+	p.Start()
+	defer p.Close()
+
+	var want []byte
+	for rem := 2; rem >= 0; rem-- {
+		data := make([]byte, 200)
+		for i := range data {
+			data[i] = byte(rem)
+		}
+		want = append(want, data...)
+
+		x := newXUDT(0)
+		x.Data = params.NewData(data)
+		x.Segmentation = params.NewSegmentation(rem == 2, 1, uint8(rem), 1)
+		x.Importance = params.NewImportance(3)
+		x.SetPointers()
+		if err := p.A.Send(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs, err := p.BReceived.Wait(1, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := msgs[0].(*sccp.XUDT)
+	if got := x.Data.Value(); string(got) != string(want) {
+		t.Errorf("got %d octets, want %d", len(got), len(want))
+	}
+	if x.Segmentation != nil || x.Importance != nil || x.EndOfOptionalParameters != nil {
+		t.Errorf("got %v delivered, want no optional parameters", x)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	"github.com/cgngc/go-sccp/params"
 )
 
 // SCMGType is type of SCMG message.
@@ -30,13 +32,13 @@ const (
 type SCMG struct {
 	Type                           SCMGType
 	AffectedSSN                    uint8
-	AffectedPC                     uint16
+	AffectedPC                     params.PointCode
 	SubsystemMultiplicityIndicator uint8
 	SCCPCongestionLevel            uint8
 }
 
 // NewSCMG creates a new SCMG.
-func NewSCMG(typ SCMGType, assn uint8, apc uint16, smi uint8, scl uint8) *SCMG {
+func NewSCMG(typ SCMGType, assn uint8, apc params.PointCode, smi uint8, scl uint8) *SCMG {
 	return &SCMG{
 		Type:                           typ,
 		AffectedSSN:                    assn,
@@ -64,9 +66,13 @@ func (s *SCMG) MarshalTo(b []byte) error {
 		return io.ErrUnexpectedEOF
 	}
 
+	if s.AffectedPC > params.MaxITUPointCode {
+		return fmt.Errorf("point code %s does not fit in 14 bits", s.AffectedPC)
+	}
+
 	b[0] = uint8(s.Type)
 	b[1] = s.AffectedSSN
-	binary.LittleEndian.PutUint16(b[2:4], s.AffectedPC)
+	binary.LittleEndian.PutUint16(b[2:4], s.AffectedPC.Uint16())
 	b[4] = s.SubsystemMultiplicityIndicator
 	if s.Type == SCMGTypeSSC {
 		b[5] = s.SCCPCongestionLevel
@@ -94,7 +100,7 @@ func (s *SCMG) UnmarshalBinary(b []byte) error {
 
 	s.Type = SCMGType(b[0])
 	s.AffectedSSN = b[1]
-	s.AffectedPC = binary.LittleEndian.Uint16(b[2:4])
+	s.AffectedPC = params.PointCode(binary.LittleEndian.Uint16(b[2:4]))
 	s.SubsystemMultiplicityIndicator = b[4]
 
 	if s.Type == SCMGTypeSSC {
@@ -107,6 +113,12 @@ func (s *SCMG) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// Reset clears all the fields in SCMG, so that the SCMG can be
+// reused for another message.
+func (s *SCMG) Reset() {
+	*s = SCMG{}
+}
+
 // MarshalLen returns the serial length.
 func (s *SCMG) MarshalLen() int {
 	// Table 24/Q.713 – SCMG messages
@@ -141,13 +153,60 @@ func (s *SCMG) MessageTypeName() string {
 	return s.Type.String()
 }
 
+// SSNSCMG is the Subsystem Number of SCCP management.
+const SSNSCMG uint8 = 1
+
+// WrapSCMG returns a UDT that carries scmg in its Data from opc to dpc.
+//
+// Both addresses are routed on SSN with SSNSCMG, and the UDT is in protocol
+// class 0 without the return option, as specified in 5.3/Q.714.
+func WrapSCMG(scmg *SCMG, opc, dpc params.PointCode) (*UDT, error) {
+	data, err := scmg.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s: %w", scmg.MessageTypeName(), err)
+	}
+
+	ai := params.NewAddressIndicator(true, true, true, params.GTINoGT)
+	return NewUDT(
+		0, false,
+		params.NewCalledPartyAddress(ai, dpc, SSNSCMG, nil),
+		params.NewCallingPartyAddress(ai, opc, SSNSCMG, nil),
+		data,
+	), nil
+}
+
+// ExtractSCMG returns the SCMG carried in m if m is a UDT or XUDT addressed to
+// SSNSCMG. The second return value is false if m does not carry a valid SCMG.
+func ExtractSCMG(m Message) (*SCMG, bool) {
+	var u Unitdata
+	switch m := m.(type) {
+	case *UDT:
+		u = m
+	case *XUDT:
+		u = m
+	default:
+		return nil, false
+	}
+
+	cdpa := u.CalledParty()
+	if cdpa == nil || !cdpa.HasSSN() || cdpa.SubsystemNumber != SSNSCMG {
+		return nil, false
+	}
+
+	scmg, err := ParseSCMG(u.Payload())
+	if err != nil {
+		return nil, false
+	}
+	return scmg, true
+}
+
 // SSN State Management Methods for SSNStateManager
 
 // HandleUserInService - Handle N-STATE Request with UIS
-func (sm *SSNStateManager) HandleUserInService(pc uint16, ssn uint8) error {
+func (sm *SSNStateManager) HandleUserInService(pc params.PointCode, ssn uint8) error {
 	entry := sm.GetEntry(pc, ssn)
 	if entry == nil {
-		return fmt.Errorf("SSN entry not found: PC=%d, SSN=%d", pc, ssn)
+		return fmt.Errorf("SSN entry not found: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	if !entry.IsLocal {
@@ -167,17 +226,17 @@ func (sm *SSNStateManager) HandleUserInService(pc uint16, ssn uint8) error {
 			sm.OnBroadcast(BroadcastSSA, entry)
 		}
 
-		logf("Local subsystem allowed: PC=%d, SSN=%d", pc, ssn)
+		logf("Local subsystem allowed: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	return nil
 }
 
 // HandleUserOutOfService - Handle N-STATE Request with UOS
-func (sm *SSNStateManager) HandleUserOutOfService(pc uint16, ssn uint8) error {
+func (sm *SSNStateManager) HandleUserOutOfService(pc params.PointCode, ssn uint8) error {
 	entry := sm.GetEntry(pc, ssn)
 	if entry == nil {
-		return fmt.Errorf("SSN entry not found: PC=%d, SSN=%d", pc, ssn)
+		return fmt.Errorf("SSN entry not found: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	if !entry.IsLocal {
@@ -200,14 +259,14 @@ func (sm *SSNStateManager) HandleUserOutOfService(pc uint16, ssn uint8) error {
 			sm.OnBroadcast(BroadcastSSP, entry)
 		}
 
-		logf("Local subsystem prohibited: PC=%d, SSN=%d", pc, ssn)
+		logf("Local subsystem prohibited: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	return nil
 }
 
 // HandleSSA - Handle remote Subsystem Allowed message
-func (sm *SSNStateManager) HandleSSA(pc uint16, ssn uint8) error {
+func (sm *SSNStateManager) HandleSSA(pc params.PointCode, ssn uint8) error {
 	entry := sm.GetEntry(pc, ssn)
 	if entry == nil {
 		entry = sm.AddEntry(pc, ssn, false)
@@ -224,14 +283,14 @@ func (sm *SSNStateManager) HandleSSA(pc uint16, ssn uint8) error {
 			sm.OnStateChange(entry, SSNStateAllowed, ReasonNetworkInitiated)
 		}
 
-		logf("Remote subsystem allowed: PC=%d, SSN=%d", pc, ssn)
+		logf("Remote subsystem allowed: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	return nil
 }
 
 // HandleSSP - Handle remote Subsystem Prohibited message
-func (sm *SSNStateManager) HandleSSP(pc uint16, ssn uint8) error {
+func (sm *SSNStateManager) HandleSSP(pc params.PointCode, ssn uint8) error {
 	entry := sm.GetEntry(pc, ssn)
 	if entry == nil {
 		entry = sm.AddEntry(pc, ssn, false)
@@ -248,14 +307,14 @@ func (sm *SSNStateManager) HandleSSP(pc uint16, ssn uint8) error {
 			sm.OnStateChange(entry, SSNStateProhibited, ReasonNetworkInitiated)
 		}
 
-		logf("Remote subsystem prohibited: PC=%d, SSN=%d", pc, ssn)
+		logf("Remote subsystem prohibited: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	return nil
 }
 
 // HandleSST - Handle Subsystem Test message
-func (sm *SSNStateManager) HandleSST(pc uint16, ssn uint8) error {
+func (sm *SSNStateManager) HandleSST(pc params.PointCode, ssn uint8) error {
 	entry := sm.GetEntry(pc, ssn)
 	if entry == nil {
 		entry = sm.AddEntry(pc, ssn, false)
@@ -269,11 +328,11 @@ func (sm *SSNStateManager) HandleSST(pc uint16, ssn uint8) error {
 			return fmt.Errorf("failed to marshal SSA response: %w", err)
 		}
 
-		logf("Responding to SST with SSA for PC=%d, SSN=%d: %x", pc, ssn, ssaBytes)
+		logf("Responding to SST with SSA for PC=%s, SSN=%d: %x", pc, ssn, ssaBytes)
 		// TODO: Send SSA response over network
 	} else if entry.IsLocal && entry.IsProhibited() {
 		// Local subsystem is prohibited, don't respond (let SST timeout)
-		logf("Local subsystem prohibited, not responding to SST: PC=%d, SSN=%d", pc, ssn)
+		logf("Local subsystem prohibited, not responding to SST: PC=%s, SSN=%d", pc, ssn)
 	}
 
 	return nil
@@ -299,7 +358,7 @@ func (sm *SSNStateManager) startSST(entry *SSNEntry) {
 
 	// Start testing
 	sm.scheduleSST(entry)
-	logf("Started SST for PC=%d, SSN=%d", entry.PointCode, entry.SSN)
+	logf("Started SST for PC=%s, SSN=%d", entry.PointCode, entry.SSN)
 }
 
 // stopSST - Stop subsystem testing
@@ -313,7 +372,7 @@ func (sm *SSNStateManager) stopSST(entry *SSNEntry) {
 	}
 
 	entry.TestRetries = 0
-	logf("Stopped SST for PC=%d, SSN=%d", entry.PointCode, entry.SSN)
+	logf("Stopped SST for PC=%s, SSN=%d", entry.PointCode, entry.SSN)
 }
 
 // scheduleSST - Schedule next SST message
@@ -328,7 +387,8 @@ func (sm *SSNStateManager) performSST(entry *SSNEntry) {
 	entry.mutex.Lock()
 	defer entry.mutex.Unlock()
 
-	if entry.IsAllowed() {
+	// IsAllowed cannot be used here as the entry is already locked.
+	if entry.State == SSNStateAllowed {
 		return // Subsystem became available, stop testing
 	}
 
@@ -340,7 +400,7 @@ func (sm *SSNStateManager) performSST(entry *SSNEntry) {
 	entry.TestRetries++
 
 	if entry.TestRetries >= entry.MaxTestRetries {
-		logf("Max SST retries reached for PC=%d, SSN=%d", entry.PointCode, entry.SSN)
+		logf("Max SST retries reached for PC=%s, SSN=%d", entry.PointCode, entry.SSN)
 		return
 	}
 
@@ -354,23 +414,31 @@ func (sm *SSNStateManager) performSST(entry *SSNEntry) {
 	sm.scheduleSST(entry)
 }
 
-// sendSST - Send SST SCMG message
-func (sm *SSNStateManager) sendSST(pc uint16, ssn uint8) error {
-	// Create SST SCMG message
-	sst := NewSCMG(SCMGTypeSST, ssn, pc, 0, 0)
+// sendSST - Send SST SCMG message through the Stack
+func (sm *SSNStateManager) sendSST(pc params.PointCode, ssn uint8) error {
+	sm.mutex.RLock()
+	send, opc := sm.send, sm.pc
+	sm.mutex.RUnlock()
 
-	// TODO: Integrate with your SCCP message sending mechanism
-	// This is where you would send the SST message over your M3UA/SCTP connection
-	logf("Sending SST to PC=%d, SSN=%d", pc, ssn)
+	if send == nil {
+		return fmt.Errorf("no Stack to send SST to PC=%s, SSN=%d", pc, ssn)
+	}
 
-	// For now, just log the message that would be sent
-	sstBytes, err := sst.MarshalBinary()
+	udt, err := WrapSCMG(NewSCMG(SCMGTypeSST, ssn, pc, 0, 0), opc, pc)
 	if err != nil {
-		return fmt.Errorf("failed to marshal SST: %w", err)
+		return err
 	}
 
-	logf("SST message bytes: %x", sstBytes)
-	return nil
+	logf("Sending SST to PC=%s, SSN=%d", pc, ssn)
+	return send(udt)
+}
+
+// setSender sets the function to send SST with, and the point code it is sent from.
+func (sm *SSNStateManager) setSender(pc params.PointCode, send func(m Message) error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.pc, sm.send = pc, send
 }
 
 // ProcessSCMGMessage - Process incoming SCMG messages
@@ -0,0 +1,117 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cgngc/go-sccp"
+	"github.com/cgngc/go-sccp/params"
+	"github.com/pascaldekloe/goe/verify"
+)
+
+func TestWrapSCMG(t *testing.T) {
+	scmg := sccp.NewSCMG(sccp.SCMGTypeSST, 6, 0x1234, 0, 0)
+
+	udt, err := sccp.WrapSCMG(scmg, 0x0021, 0x1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := udt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x09,             // MsgType
+		0x00,             // Protocol Class
+		0x03, 0x07, 0x0b, // Pointers
+		0x04, 0x43, 0x34, 0x12, 0x01, // CdPA
+		0x04, 0x43, 0x21, 0x00, 0x01, // CgPA
+		0x05, 0x03, 0x06, 0x34, 0x12, 0x00, // Data
+	}
+	if got := b; !verify.Values(t, "", got, want) {
+		t.Fail()
+	}
+
+	m, err := sccp.ParseMessage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := sccp.ExtractSCMG(m)
+	if !ok {
+		t.Fatal("got no SCMG")
+	}
+	if !verify.Values(t, "", got, scmg) {
+		t.Fail()
+	}
+
+	for _, pc := range []params.PointCode{params.MaxITUPointCode + 1, 0x10000} {
+		if _, err := sccp.WrapSCMG(sccp.NewSCMG(sccp.SCMGTypeSSA, 6, pc, 0, 0), 1, 2); err == nil {
+			t.Errorf("got no error for affected PC %s not fitting in 14 bits", pc)
+		}
+	}
+}
+
+func TestExtractSCMG(t *testing.T) {
+	scmg := []byte{0x01, 0x06, 0x34, 0x12, 0x00}
+	cdpa := params.NewCalledPartyAddress(0x42, 0, sccp.SSNSCMG, nil)
+	cgpa := params.NewCallingPartyAddress(0x42, 0, sccp.SSNSCMG, nil)
+
+	cases := []struct {
+		description string
+		msg         sccp.Message
+		ok          bool
+	}{
+		{"UDT", sccp.NewUDT(0, false, cdpa, cgpa, scmg), true},
+		{"XUDT", sccp.NewXUDT(0, false, 15, cdpa, cgpa, scmg), true},
+		{"UDTS", sccp.NewUDTS(params.ReturnCauseUnqualified, cdpa, cgpa, scmg), false},
+		{"Other SSN", sccp.NewUDT(0, false, params.NewCalledPartyAddress(0x42, 0, 6, nil), cgpa, scmg), false},
+		{"No SSN", sccp.NewUDT(0, false, params.NewCalledPartyAddress(0x01, 0x1234, 0, nil), cgpa, scmg), false},
+		{"Short Data", sccp.NewUDT(0, false, cdpa, cgpa, scmg[:4]), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, ok := sccp.ExtractSCMG(c.msg); ok != c.ok {
+				t.Errorf("got %v, want %v", ok, c.ok)
+			}
+		})
+	}
+}
+
+func TestSSTAfterSSP(t *testing.T) {
+	sm := sccp.NewSSNStateManager()
+	sm.DefaultTestInterval = time.Millisecond
+
+	if err := sm.HandleSSA(0x1234, 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.HandleSSP(0x1234, 6); err != nil {
+		t.Fatal(err)
+	}
+
+	// let the SST timer fire a few times.
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sm.HandleSSA(0x1234, 6)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SSA not processed while testing the subsystem")
+	}
+
+	if e := sm.GetEntry(0x1234, 6); !e.IsAllowed() {
+		t.Error("got prohibited, want allowed")
+	}
+}
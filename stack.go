@@ -0,0 +1,341 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// DefaultReadBufferSize is the size of the buffer the Stack reads a message into.
+// It is large enough for a LUDT of the maximum length.
+const DefaultReadBufferSize = 4096
+
+// Stack is a SCCP entity that sends and receives the messages over a Transport.
+//
+// The received unitdata messages are passed through Router if set, and delivered
+// to OnMessage. The messages rejected by Router are returned to the originator
+// if the return option is set, and the ones Router fails to route are discarded
+// and reported to OnError.
+//
+// The SCMG messages carried in UDT or XUDT addressed to SSNSCMG are processed by
+// SSNStateManager instead of being delivered, unless SSNStateManager is nil. The
+// SST for the prohibited remote subsystems are sent by the Stack.
+//
+// The segmented XUDT are reassembled by Reassembler if set, and delivered as an
+// XUDT without the optional parameters that carries the whole user data. Such an
+// XUDT cannot be serialized if the user data exceeds the length of an XUDT. The XUDTS for the
+// failed reassembly are sent by the Stack.
+//
+// The payload of the delivered messages is decoded by PayloadHandler if set, in
+// addition to OnMessage.
+//
+// The messages are parsed without copying the read buffer, which is reused for
+// the next message. The delivered messages are valid only during the call to
+// PayloadHandler and OnMessage unless RetainMessages is set.
+type Stack struct {
+	PointCode params.PointCode
+	Transport Transport
+
+	Router          *Router
+	SSNStateManager *SSNStateManager
+	Reassembler     *Reassembler
+	PayloadHandler  *PayloadHandler
+
+	// Tracer receives every message sent or received by the Stack if set.
+	Tracer Tracer
+
+	// Configuration
+	Limits         Limits
+	ReadBufferSize int
+
+	// RetainMessages makes the delivered messages hold their own copy of the
+	// user data and the addresses, so that they can be retained after OnMessage
+	// returns, e.g., to be processed in another goroutine.
+	RetainMessages bool
+
+	// Callbacks
+	OnMessage func(m Message)
+	OnError   func(err error)
+
+	mu     sync.Mutex
+	wmu    sync.Mutex
+	wbuf   []byte
+	closed bool
+
+	inSeq, outSeq atomic.Uint64
+	seqMu         sync.Mutex
+	peerSeq       map[peerSeqKey]uint64
+}
+
+type peerSeqKey struct {
+	peer string
+	out  bool
+}
+
+// NewStack creates a new Stack with the local point code, running on t.
+func NewStack(pc params.PointCode, t Transport) *Stack {
+	return &Stack{
+		PointCode:       pc,
+		Transport:       t,
+		SSNStateManager: NewSSNStateManager(),
+		Limits:          DefaultLimits(),
+		ReadBufferSize:  DefaultReadBufferSize,
+	}
+}
+
+// Send serializes m and writes it to the Transport.
+//
+// It returns *LengthError without sending anything if m exceeds the Limits.
+func (s *Stack) Send(m Message) error {
+	if err := s.Limits.Check(m); err != nil {
+		s.traceOut(m, nil, err)
+		return err
+	}
+
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+
+	var err error
+	s.wbuf, err = AppendMessage(s.wbuf[:0], m)
+	if err != nil {
+		err = fmt.Errorf("failed to serialize %s: %w", m.MessageTypeName(), err)
+		s.traceOut(m, nil, err)
+		return err
+	}
+
+	_, err = s.Transport.Write(s.wbuf)
+	s.traceOut(m, s.wbuf, err)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", m.MessageTypeName(), err)
+	}
+	return nil
+}
+
+// Serve reads the messages from the Transport and handles them until the Transport
+// is closed. It returns nil if the Stack or the Transport is closed.
+func (s *Stack) Serve() error {
+	size := s.ReadBufferSize
+	if size <= 0 {
+		size = DefaultReadBufferSize
+	}
+
+	if s.Reassembler != nil {
+		s.Reassembler.setSender(s.Send)
+	}
+	if s.SSNStateManager != nil {
+		s.SSNStateManager.setSender(s.PointCode, s.Send)
+	}
+
+	buf := make([]byte, size)
+	for {
+		n, err := s.Transport.Read(buf)
+		at := time.Now()
+		if err != nil {
+			if s.isClosed() || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to read from transport: %w", err)
+		}
+
+		s.handle(buf[:n], at)
+	}
+}
+
+// Close closes the Stack and its Transport.
+func (s *Stack) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	return s.Transport.Close()
+}
+
+func (s *Stack) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+func (s *Stack) handle(b []byte, at time.Time) {
+	var opts []params.DataOption
+	if s.RetainMessages {
+		opts = append(opts, params.WithOwnedData())
+	}
+
+	m, err := ParseMessage(b, opts...)
+	if err != nil {
+		err = fmt.Errorf("failed to parse message %x: %w", b, err)
+		s.trace(at, nil, b, nil, err)
+		s.error(err)
+		return
+	}
+	if u, ok := m.(Unitdata); ok && s.RetainMessages {
+		u.SetPartyAddresses(cloneAddress(u.CalledParty()), cloneAddress(u.CallingParty()))
+	}
+
+	if s.SSNStateManager != nil {
+		if scmg, ok := ExtractSCMG(m); ok {
+			s.trace(at, m, b, nil, nil)
+			if err := s.SSNStateManager.ProcessSCMGMessage(scmg); err != nil {
+				s.error(fmt.Errorf("failed to process %s: %w", scmg.MessageTypeName(), err))
+			}
+			return
+		}
+	}
+
+	if s.Router == nil {
+		s.trace(at, m, b, nil, nil)
+		s.deliver(m)
+		return
+	}
+
+	res, err := s.Router.Route(m)
+	if err != nil {
+		if errors.Is(err, ErrNotUnitdata) {
+			// not routable: deliver as is.
+			s.trace(at, m, b, nil, nil)
+			s.deliver(m)
+			return
+		}
+
+		// do not let a broken policy bypass the screening.
+		err = fmt.Errorf("failed to route %s: %w", m.MessageTypeName(), err)
+		s.trace(at, m, b, nil, err)
+		s.error(err)
+		return
+	}
+
+	s.trace(at, m, b, res, nil)
+
+	if !res.Rejected {
+		s.deliver(res.Message)
+		return
+	}
+
+	if res.Return != nil {
+		if err := s.Send(res.Return); err != nil {
+			s.error(err)
+		}
+	}
+}
+
+// trace passes a received message to Tracer.
+func (s *Stack) trace(at time.Time, m Message, b []byte, res *RouteResult, err error) {
+	if s.Tracer == nil {
+		return
+	}
+
+	peer := peerOf(m, false)
+	s.Tracer.OnMessageIn(&TraceEvent{
+		Seq:     s.inSeq.Add(1),
+		Time:    at,
+		Peer:    peer,
+		PeerSeq: s.nextPeerSeq(peer, false),
+		Message: m,
+		Raw:     b,
+		Route:   res,
+		Err:     err,
+	})
+}
+
+// traceOut passes a sent message to Tracer. b is nil if m is not serialized.
+func (s *Stack) traceOut(m Message, b []byte, err error) {
+	if s.Tracer == nil {
+		return
+	}
+
+	peer := peerOf(m, true)
+	s.Tracer.OnMessageOut(&TraceEvent{
+		Seq:     s.outSeq.Add(1),
+		Time:    time.Now(),
+		Peer:    peer,
+		PeerSeq: s.nextPeerSeq(peer, true),
+		Message: m,
+		Raw:     b,
+		Err:     err,
+	})
+}
+
+// nextPeerSeq returns the next sequence number of the messages exchanged with
+// peer in the direction. It returns 0 for the empty peer.
+func (s *Stack) nextPeerSeq(peer string, out bool) uint64 {
+	if peer == "" {
+		return 0
+	}
+
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	if s.peerSeq == nil {
+		s.peerSeq = make(map[peerSeqKey]uint64)
+	}
+	key := peerSeqKey{peer, out}
+	s.peerSeq[key]++
+	return s.peerSeq[key]
+}
+
+func (s *Stack) deliver(m Message) {
+	if x, ok := m.(*XUDT); ok && x.Segmentation != nil && s.Reassembler != nil {
+		data, ok := s.Reassembler.Reassemble(x)
+		if !ok {
+			return
+		}
+
+		// without the optional part, the pointers do not depend on the length
+		// of the user data, which may not fit in an XUDT.
+		whole := *x
+		whole.Data = params.NewData(data)
+		whole.Segmentation = nil
+		whole.Importance = nil
+		whole.EndOfOptionalParameters = nil
+		whole.SetPointers()
+		m = &whole
+	}
+
+	if s.PayloadHandler != nil {
+		s.PayloadHandler.HandleMessage(m)
+	}
+	if s.OnMessage != nil {
+		s.OnMessage(m)
+	}
+}
+
+// cloneAddress returns a copy of p that does not refer to the byte sequence p is
+// parsed from.
+func cloneAddress(p *params.PartyAddress) *params.PartyAddress {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+	if p.GlobalTitle != nil {
+		gt := *p.GlobalTitle
+		gt.AddressInformation = bytes.Clone(gt.AddressInformation)
+		c.GlobalTitle = &gt
+	}
+	return &c
+}
+
+func (s *Stack) error(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+		return
+	}
+	logf("Stack PC=%s: %s", s.PointCode, err)
+}
@@ -0,0 +1,189 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TraceEvent is a message sent or received by a Stack, passed to Tracer.
+type TraceEvent struct {
+	// Seq is the sequence number of the message in its direction, starting from 1
+	// for each Stack.
+	Seq  uint64
+	Time time.Time
+
+	// Peer is the address of the peer in the format of params.PartyAddress.Address:
+	// the Calling Party Address of a received message, and the Called Party
+	// Address of a sent one. It is empty if Message is not a unitdata message.
+	//
+	// PeerSeq is the sequence number of the message among the ones exchanged
+	// with Peer in its direction, starting from 1 for each Stack.
+	Peer    string
+	PeerSeq uint64
+
+	// Message is the decoded message. It is nil if Raw could not be decoded, with
+	// the reason in Err.
+	Message Message
+
+	// Raw is the byte sequence read from or written to the Transport. It is nil if
+	// Message could not be serialized, with the reason in Err.
+	// It is valid only during the call to Tracer; copy it to retain.
+	Raw []byte
+
+	// Route is the decision of Router on a received message. It is nil for the
+	// sent messages, and the received ones not passed through Router.
+	Route *RouteResult
+
+	// Err is the error that occurred in decoding, routing, serializing or sending
+	// the message.
+	Err error
+}
+
+// String returns the TraceEvent in human readable format.
+func (e *TraceEvent) String() string {
+	s := fmt.Sprintf("#%d %s", e.Seq, e.Time.Format(time.RFC3339Nano))
+	if e.Peer != "" {
+		s += fmt.Sprintf(" %s#%d", e.Peer, e.PeerSeq)
+	}
+	if e.Message != nil {
+		s += " " + e.Message.MessageTypeName()
+	}
+	if e.Route != nil {
+		s += " " + e.Route.String()
+	}
+	if e.Err != nil {
+		s += " error: " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %x", s, e.Raw)
+}
+
+// Tracer receives every message sent or received by a Stack.
+//
+// The methods are called synchronously in the path of the message; an
+// implementation that does a slow I/O should do it in background.
+type Tracer interface {
+	OnMessageIn(e *TraceEvent)
+	OnMessageOut(e *TraceEvent)
+}
+
+// MultiTracer returns a Tracer that passes the events to all the tracers in order.
+func MultiTracer(tracers ...Tracer) Tracer {
+	return multiTracer(tracers)
+}
+
+type multiTracer []Tracer
+
+func (t multiTracer) OnMessageIn(e *TraceEvent) {
+	for _, tr := range t {
+		tr.OnMessageIn(e)
+	}
+}
+
+func (t multiTracer) OnMessageOut(e *TraceEvent) {
+	for _, tr := range t {
+		tr.OnMessageOut(e)
+	}
+}
+
+// PeerStats is the statistics of the messages exchanged with a peer.
+type PeerStats struct {
+	In, Out  uint64
+	Rejected uint64
+	Errors   uint64
+
+	LastIn, LastOut time.Time
+}
+
+// TraceStats is a Tracer that counts the messages per peer.
+//
+// The peer of a received message is its Calling Party Address, and the one of a
+// sent message is its Called Party Address. The messages that are not unitdata
+// or not decoded are counted under the empty peer.
+//
+// TraceStats is safe for concurrent use.
+type TraceStats struct {
+	mu    sync.Mutex
+	peers map[string]*PeerStats
+}
+
+// NewTraceStats creates a new TraceStats.
+func NewTraceStats() *TraceStats {
+	return &TraceStats{
+		peers: make(map[string]*PeerStats),
+	}
+}
+
+// OnMessageIn counts a received message.
+func (s *TraceStats) OnMessageIn(e *TraceEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.peer(e, false)
+	p.In++
+	p.LastIn = e.Time
+	if e.Route != nil && e.Route.Rejected {
+		p.Rejected++
+	}
+	if e.Err != nil {
+		p.Errors++
+	}
+}
+
+// OnMessageOut counts a sent message.
+func (s *TraceStats) OnMessageOut(e *TraceEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.peer(e, true)
+	p.Out++
+	p.LastOut = e.Time
+	if e.Err != nil {
+		p.Errors++
+	}
+}
+
+func (s *TraceStats) peer(e *TraceEvent, out bool) *PeerStats {
+	key := peerOf(e.Message, out)
+	p, ok := s.peers[key]
+	if !ok {
+		p = &PeerStats{}
+		s.peers[key] = p
+	}
+	return p
+}
+
+// peerOf returns the address of the peer m is received from, or sent to if out is
+// true. It is empty if m is not a unitdata message.
+func peerOf(m Message, out bool) string {
+	u, ok := m.(Unitdata)
+	if !ok {
+		return ""
+	}
+
+	addr := u.CallingParty()
+	if out {
+		addr = u.CalledParty()
+	}
+	if addr == nil {
+		return ""
+	}
+	return addr.Address()
+}
+
+// Peers returns a snapshot of the statistics keyed by the address of the peers
+// in the format of params.PartyAddress.Address.
+func (s *TraceStats) Peers() map[string]PeerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	peers := make(map[string]PeerStats, len(s.peers))
+	for k, p := range s.peers {
+		peers[k] = *p
+	}
+	return peers
+}
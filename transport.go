@@ -0,0 +1,16 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+// Transport is an interface to exchange the serialized SCCP messages with the lower
+// layer, e.g., M3UA. Each Read and Write is expected to carry exactly one message.
+//
+// *m3ua.Conn in github.com/wmnsk/go-m3ua and net.Conn of a packet-oriented network
+// satisfy Transport.
+type Transport interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	Close() error
+}
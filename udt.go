@@ -132,10 +132,13 @@ func (u *UDT) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 // SCCP is dependent on the Pointers when serializing, which means that it might fail when invalid Pointers are set.
-// Enhanced MarshalTo function using library only
+//
+// It returns *LengthError if the parameters do not fit in the size limits of UDT.
 func (u *UDT) MarshalTo(b []byte) error {
-	l := len(b)
-	if l < 6 {
+	if err := u.checkLength(); err != nil {
+		return err
+	}
+	if len(b) < u.MarshalLen() {
 		return io.ErrUnexpectedEOF
 	}
 
@@ -153,8 +156,8 @@ func (u *UDT) MarshalTo(b []byte) error {
 	b[n+2] = u.ptr3
 	n += 3
 
-	cdpaEnd := int(u.ptr2 + 3)
-	cgpaEnd := int(u.ptr3 + 4)
+	cdpaEnd := int(u.ptr2) + 3
+	cgpaEnd := int(u.ptr3) + 4
 
 	if _, err := u.CalledPartyAddress.Write(b[n:cdpaEnd]); err != nil {
 		return err
@@ -199,34 +202,48 @@ func NewUDT(pcls int, retOnErr bool, cdpa, cgpa *params.PartyAddress, data []byt
 		CallingPartyAddress: cgpa,
 		Data:                params.NewData(data),
 	}
-
-	u.ptr1 = 3
-	u.ptr2 = u.ptr1 + uint8(cdpa.MarshalLen()) - 1
-	u.ptr3 = u.ptr2 + uint8(cgpa.MarshalLen()) - 1
+	u.SetPointers()
 
 	return u
 }
 
-// Enhanced MarshalLen calculation
-func (u *UDT) MarshalLen() int {
-	l := 1 // Message Type
-	l += 1 // Protocol Class
-	l += 1 // SLS
-	l += 3 // Three pointer bytes
+// SetPointers sets the Pointers calculated from the current parameters.
+// This should be called after changing the variable length parameters in UDT.
+func (u *UDT) SetPointers() {
+	u.ptr1 = 3
+	u.ptr2 = u.ptr1 + uint8(u.CalledPartyAddress.MarshalLen()) - 1
+	u.ptr3 = u.ptr2 + uint8(u.CallingPartyAddress.MarshalLen()) - 1
+}
 
-	// Called Party Address - use actual address length (11 bytes) not MarshalLen (12 bytes)
-	if u.CalledPartyAddress != nil {
-		l += 1 + 11 // length byte + 11 address bytes (without library's length prefix)
+// checkLength returns *LengthError if the Pointers or the Data do not fit in one octet.
+func (u *UDT) checkLength() error {
+	if l := u.Data.Len(); l > 0xff {
+		return &LengthError{MsgTypeUDT, "Data", l, 0xff}
 	}
 
-	// Calling Party Address - same approach
-	if u.CallingPartyAddress != nil {
-		l += 1 + 11 // length byte + 11 address bytes
+	// the Pointer to Data: 1 + CdPA + CgPA
+	if l := u.CalledPartyAddress.MarshalLen() + u.CallingPartyAddress.MarshalLen(); l > 0xff-1 {
+		return &LengthError{MsgTypeUDT, "Called/Calling Party Address", l, 0xff - 1}
 	}
 
-	// Data
-	if u.Data != nil {
-		l += 1 + u.Data.MarshalLen() // length byte + data
+	return nil
+}
+
+// Reset clears all the fields in UDT and the Pointers, so that the UDT can be
+// reused for another message.
+func (u *UDT) Reset() {
+	*u = UDT{}
+}
+
+// MarshalLen returns the serial length.
+func (u *UDT) MarshalLen() int {
+	l := 5 // MsgType + ProtocolClass + Pointers
+	l += u.CalledPartyAddress.MarshalLen()
+	l += u.CallingPartyAddress.MarshalLen()
+	if param := u.Data; param != nil {
+		l += param.MarshalLen()
+	} else {
+		l++ // length of empty Data
 	}
 
 	return l
@@ -443,10 +460,27 @@ func createSCCPAddress(ai uint8, pc uint16, ssn uint8) []byte {
 	return addr
 }
 
+// ParseUDT decodes given byte sequence as a SCCP UDT.
+//
+// The Data refers to b without copying by default. Give params.WithOwnedData to
+// make a copy.
+func ParseUDT(b []byte, opts ...params.DataOption) (*UDT, error) {
+	u := &UDT{}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	u.Data.Apply(opts...)
+
+	return u, nil
+}
+
 // UnmarshalBinary sets the values retrieved from byte sequence in a SCCP UDT.
-/*func (u *UDT) UnmarshalBinary(b []byte) error {
+//
+// Data refers to b without copying. Call Data.Own if the UDT is retained after b
+// is reused.
+func (u *UDT) UnmarshalBinary(b []byte) error {
 	l := len(b)
-	if l <= 6 {
+	if l <= 4 {
 		return io.ErrUnexpectedEOF
 	}
 
@@ -460,17 +494,6 @@ func createSCCPAddress(ai uint8, pc uint16, ssn uint8) []byte {
 	}
 	offset += n
 
-	// protocol class validation after parsing
-	if err := u.ValidateProtocolClass(); err != nil {
-		return fmt.Errorf("invalid protocol class in UDT: %w", err)
-	}
-
-	//b[n] = u.SLS
-	//n += 1 // <-- Increment offset
-
-	u.SLS = b[offset] // Read SLS from byte array
-	offset += 1
-
 	u.ptr1 = b[offset]
 	offsetPtr1 := 2 + int(u.ptr1)
 	if l < offsetPtr1+1 { // where CdPA starts
@@ -483,7 +506,7 @@ func createSCCPAddress(ai uint8, pc uint16, ssn uint8) []byte {
 	}
 	u.ptr3 = b[offset+2]
 	offsetPtr3 := 4 + int(u.ptr3)
-	if l < offsetPtr3+1 { // where u.Data starts
+	if l < offsetPtr3+1 { // where Data starts
 		return io.ErrUnexpectedEOF
 	}
 
@@ -510,180 +533,14 @@ func createSCCPAddress(ai uint8, pc uint16, ssn uint8) []byte {
 		return err
 	}
 
-	u.Data = &params.Data{}
-	if _, err := u.Data.Read(b[offsetPtr3:dataEnd]); err != nil {
-		return err
-	}
-
-	return nil
-}*/
-
-// UnmarshalBinary sets the values retrieved from byte sequence in a SCCP UDT.
-/*func (u *UDT) UnmarshalBinary(b []byte) error {
-	l := len(b)
-	if l <= 4 {
-		return io.ErrUnexpectedEOF
-	}
-
-	u.Type = MsgType(b[0])
-
-	// Start reading pointers from position 1 (no protocol class and SLS)
-	offset := 1
-
-	u.ptr1 = b[offset]
-	u.ptr2 = b[offset+1]
-	u.ptr3 = b[offset+2]
-
-	// Calculate absolute positions - pointers are relative to the pointer section start
-	ptrSectionStart := offset
-	offsetPtr1 := ptrSectionStart + int(u.ptr1)
-	offsetPtr2 := ptrSectionStart + int(u.ptr2)
-	offsetPtr3 := ptrSectionStart + int(u.ptr3)
-
-	if l < offsetPtr1+1 { // where CdPA starts
-		return io.ErrUnexpectedEOF
-	}
-	if l < offsetPtr2+1 { // where CgPA starts
-		return io.ErrUnexpectedEOF
-	}
-	if l < offsetPtr3+1 { // where u.Data starts
-		return io.ErrUnexpectedEOF
-	}
-
-	cdpaEnd := offsetPtr1 + int(b[offsetPtr1]) + 1 // +1 is the data length included from the beginning
-	if l < cdpaEnd {                               // where CdPA ends
-		return io.ErrUnexpectedEOF
-	}
-	cgpaEnd := offsetPtr2 + int(b[offsetPtr2]) + 1
-	if l < cgpaEnd { // where CgPA ends
-		return io.ErrUnexpectedEOF
-	}
-	dataEnd := offsetPtr3 + int(b[offsetPtr3]) + 1
-	if l < dataEnd { // where Data ends
-		return io.ErrUnexpectedEOF
-	}
-
-	var err error
-	u.CalledPartyAddress, _, err = params.ParseCalledPartyAddress(b[offsetPtr1:cdpaEnd])
+	u.Data, _, err = params.ParseData(b[offsetPtr3:dataEnd])
 	if err != nil {
 		return err
 	}
 
-	u.CallingPartyAddress, _, err = params.ParseCallingPartyAddress(b[offsetPtr2:cgpaEnd])
-	if err != nil {
-		return err
-	}
-
-	u.Data = &params.Data{}
-	if _, err = u.Data.Read(b[offsetPtr3:dataEnd]); err != nil {
-		return err
-	}
-
 	return nil
-}*/
-func (u *UDT) UnmarshalBinary(b []byte) error {
-	l := len(b)
-	if l <= 4 {
-		return io.ErrUnexpectedEOF
-	}
-
-	u.Type = MsgType(b[0])
-
-	offset := 1
-	u.ptr1 = b[offset]
-	u.ptr2 = b[offset+1]
-	u.ptr3 = b[offset+2]
-
-	// Called Party Address
-	offsetPtr1 := offset + int(u.ptr1) // 1 + 3 = 4
-	if l < offsetPtr1+1 {
-		return io.ErrUnexpectedEOF
-	}
-	cdpaLen := int(b[offsetPtr1])       // 0x0b = 11
-	cdpaEnd := offsetPtr1 + cdpaLen + 1 // 4 + 11 + 1 = 16
-
-	// Calling Party Address - starts after Called Party Address
-	offsetPtr2 := offset + int(u.ptr2) // 1 + 14 = 15, but this should be relative to start
-	// Actually, let's recalculate based on the actual structure
-	offsetPtr2 = cdpaEnd // Start right after Called Party Address = 16
-	if l < offsetPtr2+1 {
-		return io.ErrUnexpectedEOF
-	}
-	cgpaLen := int(b[offsetPtr2]) // Should be the length at position 16
-	cgpaEnd := offsetPtr2 + cgpaLen + 1
-
-	// Data section
-	offsetPtr3 := cgpaEnd
-	if l < offsetPtr3+1 {
-		return io.ErrUnexpectedEOF
-	}
-	dataLen := int(b[offsetPtr3])
-	dataEnd := offsetPtr3 + dataLen + 1
-
-	if l < cdpaEnd || l < cgpaEnd || l < dataEnd {
-		return io.ErrUnexpectedEOF
-	}
-
-	var err error
-	u.CalledPartyAddress, _, err = params.ParseCalledPartyAddress(b[offsetPtr1:cdpaEnd])
-	if err != nil {
-		return err
-	}
-
-	u.CallingPartyAddress, _, err = params.ParseCallingPartyAddress(b[offsetPtr2:cgpaEnd])
-	if err != nil {
-		return err
-	}
-
-	u.Data = &params.Data{}
-	if _, err = u.Data.Read(b[offsetPtr3:dataEnd]); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// MarshalLen returns the serial length.
-/*func (u *UDT) MarshalLen() int {
-	l := 6 // MsgType, ProtocolClass, pointers
-
-	l += int(u.ptr3) - 1 // length without Data
-	if param := u.Data; param != nil {
-		l += param.MarshalLen()
-	}
-
-	return l
 }
-*/
-/*func (u *UDT) MarshalLen() int {
-	l := 1 // Message Type
-	l += 1 // Protocol Class - THIS WAS MISSING!
-	l += 3 // Three pointer bytes
-
-	// Called Party Address
-	if u.CalledPartyAddress != nil {
-		l += 1 + u.CalledPartyAddress.MarshalLen() // length byte + address
-	} else {
-		l += 1 // Just length byte (0)
-	}
-
-	// Calling Party Address
-	if u.CallingPartyAddress != nil {
-		l += 1 + u.CallingPartyAddress.MarshalLen() // length byte + address
-	} else {
-		l += 1 // Just length byte (0)
-	}
 
-	// Data
-	if u.Data != nil {
-		l += 1 + u.Data.MarshalLen() // length byte + data
-	} else {
-		l += 1 // Just length byte (0)
-	}
-
-	return l
-}
-*/
 // String returns the UDT values in human readable format.
 func (u *UDT) String() string {
 	return fmt.Sprintf("%s: {ProtocolClass: %s, SLS: %d, CalledPartyAddress: %v, CallingPartyAddress: %v, Data: %s}",
@@ -696,6 +553,28 @@ func (u *UDT) String() string {
 	)
 }
 
+// CalledParty returns the CalledPartyAddress in UDT.
+func (u *UDT) CalledParty() *params.PartyAddress {
+	return u.CalledPartyAddress
+}
+
+// CallingParty returns the CallingPartyAddress in UDT.
+func (u *UDT) CallingParty() *params.PartyAddress {
+	return u.CallingPartyAddress
+}
+
+// Payload returns the value of Data in UDT, without copying.
+func (u *UDT) Payload() []byte {
+	return u.Data.Value()
+}
+
+// SetPartyAddresses replaces the Called and Calling Party Address in UDT.
+// The Pointers are updated accordingly.
+func (u *UDT) SetPartyAddresses(cdpa, cgpa *params.PartyAddress) {
+	u.CalledPartyAddress, u.CallingPartyAddress = cdpa, cgpa
+	u.SetPointers()
+}
+
 // MessageType returns the Message Type in int.
 func (u *UDT) MessageType() MsgType {
 	return MsgTypeUDT
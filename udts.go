@@ -0,0 +1,249 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// UDTS represents a SCCP Message Unitdata service (UDTS).
+//
+// UDTS is sent back to the originator of a UDT that could not be delivered, when the
+// return option is set in it.
+type UDTS struct {
+	Type                MsgType
+	ReturnCause         *params.ReturnCause
+	CalledPartyAddress  *params.PartyAddress
+	CallingPartyAddress *params.PartyAddress
+	Data                *params.Data
+}
+
+// NewUDTS creates a new UDTS.
+func NewUDTS(cause params.ReturnCauseValue, cdpa, cgpa *params.PartyAddress, data []byte) *UDTS {
+	return &UDTS{
+		Type:                MsgTypeUDTS,
+		ReturnCause:         params.NewCause(cause),
+		CalledPartyAddress:  cdpa,
+		CallingPartyAddress: cgpa,
+		Data:                params.NewData(data),
+	}
+}
+
+// MarshalBinary returns the byte sequence generated from a UDTS instance.
+func (u *UDTS) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+//
+// Unlike UDT and XUDT, the Pointers are calculated from the parameters on the fly.
+//
+// It returns *LengthError if the parameters do not fit in the size limits of UDTS.
+func (u *UDTS) MarshalTo(b []byte) error {
+	if err := u.checkLength(); err != nil {
+		return err
+	}
+	if len(b) < u.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = uint8(u.Type)
+	if _, err := u.ReturnCause.Write(b[1:]); err != nil {
+		return err
+	}
+
+	cdpaLen := u.CalledPartyAddress.MarshalLen()
+	cgpaLen := u.CallingPartyAddress.MarshalLen()
+	b[2] = 3
+	b[3] = uint8(2 + cdpaLen)
+	b[4] = uint8(1 + cdpaLen + cgpaLen)
+
+	n := 5
+	if _, err := u.CalledPartyAddress.Write(b[n : n+cdpaLen]); err != nil {
+		return err
+	}
+	n += cdpaLen
+
+	if _, err := u.CallingPartyAddress.Write(b[n : n+cgpaLen]); err != nil {
+		return err
+	}
+	n += cgpaLen
+
+	if _, err := u.data().Write(b[n:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkLength returns *LengthError if the Pointers or the Data do not fit in one octet.
+func (u *UDTS) checkLength() error {
+	if l := u.Data.Len(); l > 0xff {
+		return &LengthError{MsgTypeUDTS, "Data", l, 0xff}
+	}
+
+	// the Pointer to Data: 1 + CdPA + CgPA
+	if l := u.CalledPartyAddress.MarshalLen() + u.CallingPartyAddress.MarshalLen(); l > 0xff-1 {
+		return &LengthError{MsgTypeUDTS, "Called/Calling Party Address", l, 0xff - 1}
+	}
+
+	return nil
+}
+
+// ParseUDTS decodes given byte sequence as a SCCP UDTS.
+//
+// The Data refers to b without copying by default. Give params.WithOwnedData to
+// make a copy.
+func ParseUDTS(b []byte, opts ...params.DataOption) (*UDTS, error) {
+	u := &UDTS{}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	u.Data.Apply(opts...)
+
+	return u, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SCCP UDTS.
+//
+// Data refers to b without copying. Call Data.Own if the UDTS is retained after b
+// is reused.
+func (u *UDTS) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l <= 5 {
+		return io.ErrUnexpectedEOF
+	}
+
+	u.Type = MsgType(b[0])
+
+	var err error
+	u.ReturnCause, _, err = params.ParseReturnCause(b[1:2])
+	if err != nil {
+		return err
+	}
+
+	offsetPtr1 := 2 + int(b[2])
+	if l < offsetPtr1+1 { // where CdPA starts
+		return io.ErrUnexpectedEOF
+	}
+	offsetPtr2 := 3 + int(b[3])
+	if l < offsetPtr2+1 { // where CgPA starts
+		return io.ErrUnexpectedEOF
+	}
+	offsetPtr3 := 4 + int(b[4])
+	if l < offsetPtr3+1 { // where Data starts
+		return io.ErrUnexpectedEOF
+	}
+
+	cdpaEnd := offsetPtr1 + int(b[offsetPtr1]) + 1 // +1 is the data length included from the beginning
+	if l < cdpaEnd {                               // where CdPA ends
+		return io.ErrUnexpectedEOF
+	}
+	cgpaEnd := offsetPtr2 + int(b[offsetPtr2]) + 1
+	if l < cgpaEnd { // where CgPA ends
+		return io.ErrUnexpectedEOF
+	}
+	dataEnd := offsetPtr3 + int(b[offsetPtr3]) + 1
+	if l < dataEnd { // where Data ends
+		return io.ErrUnexpectedEOF
+	}
+
+	u.CalledPartyAddress, _, err = params.ParseCalledPartyAddress(b[offsetPtr1:cdpaEnd])
+	if err != nil {
+		return err
+	}
+
+	u.CallingPartyAddress, _, err = params.ParseCallingPartyAddress(b[offsetPtr2:cgpaEnd])
+	if err != nil {
+		return err
+	}
+
+	u.Data, _, err = params.ParseData(b[offsetPtr3:dataEnd])
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reset clears all the fields in UDTS, so that the UDTS can be
+// reused for another message.
+func (u *UDTS) Reset() {
+	*u = UDTS{}
+}
+
+// MarshalLen returns the serial length.
+func (u *UDTS) MarshalLen() int {
+	l := 5 // MsgType + ReturnCause + Pointers
+	l += u.CalledPartyAddress.MarshalLen()
+	l += u.CallingPartyAddress.MarshalLen()
+	l += u.data().MarshalLen()
+
+	return l
+}
+
+func (u *UDTS) data() *params.Data {
+	if u.Data == nil {
+		return params.NewData(nil)
+	}
+	return u.Data
+}
+
+// String returns the UDTS values in human readable format.
+func (u *UDTS) String() string {
+	return fmt.Sprintf("%s: {ReturnCause: %s, CalledPartyAddress: %v, CallingPartyAddress: %v, Data: %s}",
+		u.Type,
+		u.ReturnCause,
+		u.CalledPartyAddress,
+		u.CallingPartyAddress,
+		u.Data,
+	)
+}
+
+// CalledParty returns the CalledPartyAddress in UDTS.
+func (u *UDTS) CalledParty() *params.PartyAddress {
+	return u.CalledPartyAddress
+}
+
+// CallingParty returns the CallingPartyAddress in UDTS.
+func (u *UDTS) CallingParty() *params.PartyAddress {
+	return u.CallingPartyAddress
+}
+
+// Payload returns the value of Data in UDTS, without copying.
+func (u *UDTS) Payload() []byte {
+	return u.Data.Value()
+}
+
+// SetPartyAddresses replaces the Called and Calling Party Address in UDTS.
+func (u *UDTS) SetPartyAddresses(cdpa, cgpa *params.PartyAddress) {
+	u.CalledPartyAddress, u.CallingPartyAddress = cdpa, cgpa
+}
+
+// MessageType returns the Message Type in int.
+func (u *UDTS) MessageType() MsgType {
+	return MsgTypeUDTS
+}
+
+// MessageTypeName returns the Message Type in string.
+func (u *UDTS) MessageTypeName() string {
+	return u.MessageType().String()
+}
+
+// Cause returns the ReturnCauseValue in UDTS.
+func (u *UDTS) Cause() params.ReturnCauseValue {
+	if u.ReturnCause == nil {
+		return 0
+	}
+	return u.ReturnCause.Value()
+}
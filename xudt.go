@@ -64,6 +64,24 @@ func NewXUDT(pcls int, retOnErr bool, hc uint8, cdpa, cgpa *params.PartyAddress,
 	return x
 }
 
+// SetPointers sets the Pointers calculated from the current parameters.
+// This should be called after changing the variable length or optional parameters in XUDT.
+//
+// The pointer to the optional part is set if any of the optional parameters is
+// present, and EndOfOptionalParameters is added then.
+func (x *XUDT) SetPointers() {
+	x.ptr1 = 4
+	x.ptr2 = x.ptr1 + uint8(x.CalledPartyAddress.MarshalLen()) - 1
+	x.ptr3 = x.ptr2 + uint8(x.CallingPartyAddress.MarshalLen()) - 1
+	x.ptr4 = 0
+
+	if x.Segmentation != nil || x.Importance != nil || x.EndOfOptionalParameters != nil {
+		x.ptr4 = x.ptr3 + uint8(x.Data.MarshalLen()) - 1
+		// so that users don't have to give EndOfOptionalParameters explicitly
+		x.EndOfOptionalParameters = params.NewEndOfOptionalParameters()
+	}
+}
+
 // MarshalBinary returns the byte sequence generated from a XUDT instance.
 func (x *XUDT) MarshalBinary() ([]byte, error) {
 	b := make([]byte, x.MarshalLen())
@@ -76,7 +94,13 @@ func (x *XUDT) MarshalBinary() ([]byte, error) {
 
 // MarshalTo puts the byte sequence in the byte array given as b.
 // SCCP is dependent on the Pointers when serializing, which means that it might fail when invalid Pointers are set.
+//
+// It returns *LengthError if the parameters do not fit in the size limits of XUDT.
 func (x *XUDT) MarshalTo(b []byte) error {
+	if err := x.checkLength(); err != nil {
+		return err
+	}
+
 	l := len(b)
 	if l < 5 {
 		return io.ErrUnexpectedEOF
@@ -102,22 +126,22 @@ func (x *XUDT) MarshalTo(b []byte) error {
 		return io.ErrUnexpectedEOF
 	}
 	b[n+1] = x.ptr2
-	if p := int(x.ptr2 + 4); l < p {
+	if p := int(x.ptr2) + 4; l < p {
 		return io.ErrUnexpectedEOF
 	}
 	b[n+2] = x.ptr3
-	if p := int(x.ptr3 + 5); l < p {
+	if p := int(x.ptr3) + 5; l < p {
 		return io.ErrUnexpectedEOF
 	}
 	b[n+3] = x.ptr4
-	if p := int(x.ptr4 + 6); l < p {
+	if p := int(x.ptr4) + 6; l < p {
 		return io.ErrUnexpectedEOF
 	}
 	n += 4
 
-	cdpaEnd := int(x.ptr2 + 4)
-	cgpaEnd := int(x.ptr3 + 5)
-	dataEnd := int(x.ptr4 + 6)
+	cdpaEnd := int(x.ptr2) + 4
+	cgpaEnd := int(x.ptr3) + 5
+	dataEnd := int(x.ptr4) + 6
 	if _, err := x.CalledPartyAddress.Write(b[n:cdpaEnd]); err != nil {
 		return err
 	}
@@ -160,16 +184,23 @@ func (x *XUDT) MarshalTo(b []byte) error {
 }
 
 // ParseXUDT decodes given byte sequence as a SCCP XUDT.
-func ParseXUDT(b []byte) (*XUDT, error) {
+//
+// The Data refers to b without copying by default. Give params.WithOwnedData to
+// make a copy.
+func ParseXUDT(b []byte, opts ...params.DataOption) (*XUDT, error) {
 	x := &XUDT{}
 	if err := x.UnmarshalBinary(b); err != nil {
 		return nil, err
 	}
+	x.Data.Apply(opts...)
 
 	return x, nil
 }
 
 // UnmarshalBinary sets the values retrieved from byte sequence in a SCCP XUDT.
+//
+// Data refers to b without copying. Call Data.Own if the XUDT is retained after b
+// is reused.
 func (x *XUDT) UnmarshalBinary(b []byte) error {
 	l := len(b)
 	if l <= 5 {
@@ -265,6 +296,39 @@ func (x *XUDT) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// checkLength returns *LengthError if the Pointers or the Data do not fit in one octet.
+func (x *XUDT) checkLength() error {
+	if l := x.Data.Len(); l > 0xff {
+		return &LengthError{MsgTypeXUDT, "Data", l, 0xff}
+	}
+
+	// the Pointer to Data: 2 + CdPA + CgPA
+	l := x.CalledPartyAddress.MarshalLen() + x.CallingPartyAddress.MarshalLen()
+	if l > 0xff-2 {
+		return &LengthError{MsgTypeXUDT, "Called/Calling Party Address", l, 0xff - 2}
+	}
+
+	// the Pointer to the optional part: 1 + CdPA + CgPA + Data, which may have
+	// wrapped around to 0 when it is set.
+	if x.ptr4 != 0 || x.hasOptionalParameters() {
+		if l := l + x.Data.MarshalLen(); l > 0xff-1 {
+			return &LengthError{MsgTypeXUDT, "mandatory variable part", l, 0xff - 1}
+		}
+	}
+
+	return nil
+}
+
+func (x *XUDT) hasOptionalParameters() bool {
+	return x.Segmentation != nil || x.Importance != nil || x.EndOfOptionalParameters != nil
+}
+
+// Reset clears all the fields in XUDT and the Pointers, so that the XUDT can be
+// reused for another message.
+func (x *XUDT) Reset() {
+	*x = XUDT{}
+}
+
 // MarshalLen returns the serial length.
 func (x *XUDT) MarshalLen() int {
 	l := 7 // MsgType + ProtocolClass + HopCounter + Pointers
@@ -307,6 +371,28 @@ func (x *XUDT) String() string {
 	)
 }
 
+// CalledParty returns the CalledPartyAddress in XUDT.
+func (x *XUDT) CalledParty() *params.PartyAddress {
+	return x.CalledPartyAddress
+}
+
+// CallingParty returns the CallingPartyAddress in XUDT.
+func (x *XUDT) CallingParty() *params.PartyAddress {
+	return x.CallingPartyAddress
+}
+
+// Payload returns the value of Data in XUDT, without copying.
+func (x *XUDT) Payload() []byte {
+	return x.Data.Value()
+}
+
+// SetPartyAddresses replaces the Called and Calling Party Address in XUDT.
+// The Pointers are updated accordingly.
+func (x *XUDT) SetPartyAddresses(cdpa, cgpa *params.PartyAddress) {
+	x.CalledPartyAddress, x.CallingPartyAddress = cdpa, cgpa
+	x.SetPointers()
+}
+
 // MessageType returns the Message Type in int.
 func (x *XUDT) MessageType() MsgType {
 	return MsgTypeXUDT
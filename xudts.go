@@ -0,0 +1,360 @@
+// Copyright 2019-2024 go-sccp authors. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package sccp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cgngc/go-sccp/params"
+)
+
+// XUDTS represents a SCCP Message Extended unitdata service (XUDTS).
+//
+// XUDTS is sent back to the originator of a XUDT that could not be delivered, when
+// the return option is set in it.
+type XUDTS struct {
+	Type                    MsgType
+	ReturnCause             *params.ReturnCause
+	HopCounter              *params.HopCounter
+	CalledPartyAddress      *params.PartyAddress
+	CallingPartyAddress     *params.PartyAddress
+	Data                    *params.Data
+	Segmentation            *params.Segmentation
+	Importance              *params.Importance
+	EndOfOptionalParameters *params.EndOfOptionalParameters
+}
+
+// NewXUDTS creates a new XUDTS.
+func NewXUDTS(cause params.ReturnCauseValue, hc uint8, cdpa, cgpa *params.PartyAddress, data []byte, opts ...params.Parameter) *XUDTS {
+	x := &XUDTS{
+		Type:                MsgTypeXUDTS,
+		ReturnCause:         params.NewCause(cause),
+		HopCounter:          params.NewHopCounter(hc),
+		CalledPartyAddress:  cdpa,
+		CallingPartyAddress: cgpa,
+		Data:                params.NewData(data),
+	}
+
+	for _, opt := range opts {
+		switch opt.Code() {
+		case params.PCodeSegmentation:
+			x.Segmentation = opt.(*params.Segmentation)
+		case params.PCodeImportance:
+			x.Importance = opt.(*params.Importance)
+		case params.PCodeEndOfOptionalParameters:
+		default:
+			logf("unexpected parameter: %s in NewXUDTS", opt.Code())
+		}
+	}
+
+	if x.hasOptionalParameters() {
+		// so that users don't have to give EndOfOptionalParameters explicitly
+		x.EndOfOptionalParameters = params.NewEndOfOptionalParameters()
+	}
+
+	return x
+}
+
+// MarshalBinary returns the byte sequence generated from a XUDTS instance.
+func (x *XUDTS) MarshalBinary() ([]byte, error) {
+	b := make([]byte, x.MarshalLen())
+	if err := x.MarshalTo(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// MarshalTo puts the byte sequence in the byte array given as b.
+//
+// Unlike UDT and XUDT, the Pointers are calculated from the parameters on the fly.
+//
+// It returns *LengthError if the parameters do not fit in the size limits of XUDTS.
+func (x *XUDTS) MarshalTo(b []byte) error {
+	if err := x.checkLength(); err != nil {
+		return err
+	}
+	if len(b) < x.MarshalLen() {
+		return io.ErrUnexpectedEOF
+	}
+
+	b[0] = uint8(x.Type)
+	if _, err := x.ReturnCause.Write(b[1:]); err != nil {
+		return err
+	}
+	if _, err := x.HopCounter.Write(b[2:]); err != nil {
+		return err
+	}
+
+	cdpaLen := x.CalledPartyAddress.MarshalLen()
+	cgpaLen := x.CallingPartyAddress.MarshalLen()
+	dataLen := x.data().MarshalLen()
+	b[3] = 4
+	b[4] = uint8(3 + cdpaLen)
+	b[5] = uint8(2 + cdpaLen + cgpaLen)
+	b[6] = 0
+	if x.hasOptionalParameters() {
+		b[6] = uint8(1 + cdpaLen + cgpaLen + dataLen)
+	}
+
+	n := 7
+	if _, err := x.CalledPartyAddress.Write(b[n : n+cdpaLen]); err != nil {
+		return err
+	}
+	n += cdpaLen
+
+	if _, err := x.CallingPartyAddress.Write(b[n : n+cgpaLen]); err != nil {
+		return err
+	}
+	n += cgpaLen
+
+	if _, err := x.data().Write(b[n : n+dataLen]); err != nil {
+		return err
+	}
+	n += dataLen
+
+	if !x.hasOptionalParameters() {
+		return nil
+	}
+
+	if param := x.Segmentation; param != nil {
+		m, err := param.Write(b[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+	}
+	if param := x.Importance; param != nil {
+		m, err := param.Write(b[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+	}
+	if _, err := params.NewEndOfOptionalParameters().Write(b[n:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkLength returns *LengthError if the Pointers or the Data do not fit in one octet.
+func (x *XUDTS) checkLength() error {
+	if l := x.Data.Len(); l > 0xff {
+		return &LengthError{MsgTypeXUDTS, "Data", l, 0xff}
+	}
+
+	// the Pointer to Data: 2 + CdPA + CgPA
+	l := x.CalledPartyAddress.MarshalLen() + x.CallingPartyAddress.MarshalLen()
+	if l > 0xff-2 {
+		return &LengthError{MsgTypeXUDTS, "Called/Calling Party Address", l, 0xff - 2}
+	}
+
+	// the Pointer to the optional part: 1 + CdPA + CgPA + Data
+	if x.hasOptionalParameters() {
+		if l := l + x.data().MarshalLen(); l > 0xff-1 {
+			return &LengthError{MsgTypeXUDTS, "mandatory variable part", l, 0xff - 1}
+		}
+	}
+
+	return nil
+}
+
+// ParseXUDTS decodes given byte sequence as a SCCP XUDTS.
+//
+// The Data refers to b without copying by default. Give params.WithOwnedData to
+// make a copy.
+func ParseXUDTS(b []byte, opts ...params.DataOption) (*XUDTS, error) {
+	x := &XUDTS{}
+	if err := x.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	x.Data.Apply(opts...)
+
+	return x, nil
+}
+
+// UnmarshalBinary sets the values retrieved from byte sequence in a SCCP XUDTS.
+//
+// Data refers to b without copying. Call Data.Own if the XUDTS is retained after b
+// is reused.
+func (x *XUDTS) UnmarshalBinary(b []byte) error {
+	l := len(b)
+	if l <= 7 {
+		return io.ErrUnexpectedEOF
+	}
+
+	x.Type = MsgType(b[0])
+
+	var err error
+	x.ReturnCause, _, err = params.ParseReturnCause(b[1:2])
+	if err != nil {
+		return err
+	}
+	x.HopCounter, _, err = params.ParseHopCounter(b[2:3])
+	if err != nil {
+		return err
+	}
+
+	offsetPtr1 := 3 + int(b[3])
+	if l < offsetPtr1+1 { // where CdPA starts
+		return io.ErrUnexpectedEOF
+	}
+	offsetPtr2 := 4 + int(b[4])
+	if l < offsetPtr2+1 { // where CgPA starts
+		return io.ErrUnexpectedEOF
+	}
+	offsetPtr3 := 5 + int(b[5])
+	if l < offsetPtr3+1 { // where Data starts
+		return io.ErrUnexpectedEOF
+	}
+	ptr4 := b[6]
+	offsetPtr4 := 6 + int(ptr4)
+	if ptr4 != 0 && l < offsetPtr4+1 { // where optional parameters start
+		return io.ErrUnexpectedEOF
+	}
+
+	cdpaEnd := offsetPtr1 + int(b[offsetPtr1]) + 1 // +1 is the data length included from the beginning
+	if l < cdpaEnd {                               // where CdPA ends
+		return io.ErrUnexpectedEOF
+	}
+	cgpaEnd := offsetPtr2 + int(b[offsetPtr2]) + 1
+	if l < cgpaEnd { // where CgPA ends
+		return io.ErrUnexpectedEOF
+	}
+	dataEnd := offsetPtr3 + int(b[offsetPtr3]) + 1
+	if l < dataEnd { // where Data ends
+		return io.ErrUnexpectedEOF
+	}
+
+	x.CalledPartyAddress, _, err = params.ParseCalledPartyAddress(b[offsetPtr1:cdpaEnd])
+	if err != nil {
+		return err
+	}
+
+	x.CallingPartyAddress, _, err = params.ParseCallingPartyAddress(b[offsetPtr2:cgpaEnd])
+	if err != nil {
+		return err
+	}
+
+	x.Data, _, err = params.ParseData(b[offsetPtr3:dataEnd])
+	if err != nil {
+		return err
+	}
+
+	if ptr4 == 0 {
+		return nil
+	}
+
+	opts, _, err := params.ParseOptionalParameters(b[offsetPtr4:])
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range opts {
+		switch opt.Code() {
+		case params.PCodeSegmentation:
+			x.Segmentation = opt.(*params.Segmentation)
+		case params.PCodeImportance:
+			x.Importance = opt.(*params.Importance)
+		case params.PCodeEndOfOptionalParameters:
+			x.EndOfOptionalParameters = opt.(*params.EndOfOptionalParameters)
+		}
+	}
+
+	return nil
+}
+
+// Reset clears all the fields in XUDTS, so that the XUDTS can be
+// reused for another message.
+func (x *XUDTS) Reset() {
+	*x = XUDTS{}
+}
+
+// MarshalLen returns the serial length.
+func (x *XUDTS) MarshalLen() int {
+	l := 7 // MsgType + ReturnCause + HopCounter + Pointers
+	l += x.CalledPartyAddress.MarshalLen()
+	l += x.CallingPartyAddress.MarshalLen()
+	l += x.data().MarshalLen()
+
+	if !x.hasOptionalParameters() {
+		return l
+	}
+
+	if param := x.Segmentation; param != nil {
+		l += param.MarshalLen()
+	}
+	if param := x.Importance; param != nil {
+		l += param.MarshalLen()
+	}
+	l++ // EndOfOptionalParameters
+
+	return l
+}
+
+func (x *XUDTS) hasOptionalParameters() bool {
+	return x.Segmentation != nil || x.Importance != nil
+}
+
+func (x *XUDTS) data() *params.Data {
+	if x.Data == nil {
+		return params.NewData(nil)
+	}
+	return x.Data
+}
+
+// String returns the XUDTS values in human readable format.
+func (x *XUDTS) String() string {
+	return fmt.Sprintf("%s: {ReturnCause: %s, HopCounter: %s, CalledPartyAddress: %v, CallingPartyAddress: %v, Data: %s, Segmentation: %s, Importance: %s}",
+		x.Type,
+		x.ReturnCause,
+		x.HopCounter,
+		x.CalledPartyAddress,
+		x.CallingPartyAddress,
+		x.Data,
+		x.Segmentation,
+		x.Importance,
+	)
+}
+
+// CalledParty returns the CalledPartyAddress in XUDTS.
+func (x *XUDTS) CalledParty() *params.PartyAddress {
+	return x.CalledPartyAddress
+}
+
+// CallingParty returns the CallingPartyAddress in XUDTS.
+func (x *XUDTS) CallingParty() *params.PartyAddress {
+	return x.CallingPartyAddress
+}
+
+// Payload returns the value of Data in XUDTS, without copying.
+func (x *XUDTS) Payload() []byte {
+	return x.Data.Value()
+}
+
+// SetPartyAddresses replaces the Called and Calling Party Address in XUDTS.
+func (x *XUDTS) SetPartyAddresses(cdpa, cgpa *params.PartyAddress) {
+	x.CalledPartyAddress, x.CallingPartyAddress = cdpa, cgpa
+}
+
+// MessageType returns the Message Type in int.
+func (x *XUDTS) MessageType() MsgType {
+	return MsgTypeXUDTS
+}
+
+// MessageTypeName returns the Message Type in string.
+func (x *XUDTS) MessageTypeName() string {
+	return x.MessageType().String()
+}
+
+// Cause returns the ReturnCauseValue in XUDTS.
+func (x *XUDTS) Cause() params.ReturnCauseValue {
+	if x.ReturnCause == nil {
+		return 0
+	}
+	return x.ReturnCause.Value()
+}